@@ -17,16 +17,14 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/shoekstra/cosmic-cli/internal/cmd"
 )
 
 func main() {
-	cmd := cmd.NewCosmicCLICmd()
-	if err := cmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	if err := cmd.NewCosmicCLICmd().Execute(); err != nil {
+		cmd.PrintError(err)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -17,6 +17,9 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
 	"github.com/spf13/cobra"
@@ -37,12 +40,14 @@ func newACLCmd() *cobra.Command {
 	return cmd
 }
 
-func getACL(cfg *config.Config) ([]*cosmic.ACL, error) {
+// getACL returns the ACLs selected by the ACL, instance or network options; errors of profiles
+// that failed when others did not are added to failures.
+func getACL(ctx context.Context, cfg *config.Config, failures *cosmic.ProfileErrors) ([]*cosmic.ACL, error) {
 	acl := []*cosmic.ACL{}
 	// var err error
 
-	acls, err := cosmic.ListACLs(cosmic.NewAsyncClients(cfg))
-	if err != nil {
+	acls, err := cosmic.ListACLs(ctx, newClients(cfg))
+	if err = failures.Collect(err); err != nil {
 		return acls, err
 	}
 
@@ -52,44 +57,36 @@ func getACL(cfg *config.Config) ([]*cosmic.ACL, error) {
 	case cfg.ACLName != "":
 		acl, err = acls.FindByName(cfg.ACLName)
 	case cfg.InstanceID != "":
-		vms, e := cosmic.ListVMs(cosmic.NewAsyncClients(cfg))
-		if e != nil {
+		vms, e := cosmic.ListVMs(ctx, newClients(cfg))
+		if e = failures.Collect(e); e != nil {
 			return nil, e
 		}
 		vm, e := vms.FindByID(cfg.InstanceID)
 		if e != nil {
 			return nil, e
 		}
-		nets, e := cosmic.ListNetworks(cosmic.NewAsyncClients(cfg))
-		if e != nil {
+		nets, e := cosmic.ListNetworks(ctx, newClients(cfg))
+		if e = failures.Collect(e); e != nil {
 			return nil, e
 		}
-		net, e := nets.FindByID(vm[0].Nic[0].Networkid)
-		if e != nil {
-			return nil, e
-		}
-		acl, err = acls.FindByID(net[0].Aclid)
+		acl, err = instanceACLs(vm[0], acls, nets)
 	case cfg.InstanceName != "":
-		vms, e := cosmic.ListVMs(cosmic.NewAsyncClients(cfg))
-		if e != nil {
+		vms, e := cosmic.ListVMs(ctx, newClients(cfg))
+		if e = failures.Collect(e); e != nil {
 			return nil, e
 		}
 		vm, e := vms.FindByName(cfg.InstanceName)
 		if e != nil {
 			return nil, e
 		}
-		nets, e := cosmic.ListNetworks(cosmic.NewAsyncClients(cfg))
-		if e != nil {
-			return nil, e
-		}
-		net, e := nets.FindByID(vm[0].Nic[0].Networkid)
-		if e != nil {
+		nets, e := cosmic.ListNetworks(ctx, newClients(cfg))
+		if e = failures.Collect(e); e != nil {
 			return nil, e
 		}
-		acl, err = acls.FindByID(net[0].Aclid)
+		acl, err = instanceACLs(vm[0], acls, nets)
 	case cfg.NetworkID != "":
-		nets, e := cosmic.ListNetworks(cosmic.NewAsyncClients(cfg))
-		if e != nil {
+		nets, e := cosmic.ListNetworks(ctx, newClients(cfg))
+		if e = failures.Collect(e); e != nil {
 			return nil, e
 		}
 		net, e := nets.FindByID(cfg.NetworkID)
@@ -98,8 +95,8 @@ func getACL(cfg *config.Config) ([]*cosmic.ACL, error) {
 		}
 		acl, err = acls.FindByID(net[0].Aclid)
 	case cfg.NetworkName != "":
-		nets, e := cosmic.ListNetworks(cosmic.NewAsyncClients(cfg))
-		if e != nil {
+		nets, e := cosmic.ListNetworks(ctx, newClients(cfg))
+		if e = failures.Collect(e); e != nil {
 			return nil, e
 		}
 		net, e := nets.FindByName(cfg.NetworkName)
@@ -109,5 +106,37 @@ func getACL(cfg *config.Config) ([]*cosmic.ACL, error) {
 		acl, err = acls.FindByID(net[0].Aclid)
 	}
 
-	return acl, nil
+	return acl, err
+}
+
+// instanceACLs returns the ACLs of the networks of all NICs attached to an instance. NICs in a
+// network without an ACL, such as a network outside of a VPC, are skipped; an error is only
+// returned when none of the NICs has an ACL.
+func instanceACLs(vm *cosmic.VirtualMachine, acls cosmic.ACLs, nets cosmic.Networks) ([]*cosmic.ACL, error) {
+	if len(vm.Nic) == 0 {
+		return nil, fmt.Errorf("Instance %s has no NICs", vm.Name)
+	}
+
+	result := cosmic.ACLs{}
+	for _, nic := range vm.Nic {
+		net, err := nets.FindByID(nic.Networkid)
+		if err != nil || net[0].Aclid == "" {
+			continue
+		}
+		// Skip ACLs we've already found through another NIC.
+		if _, err := result.FindByID(net[0].Aclid); err == nil {
+			continue
+		}
+		acl, err := acls.FindByID(net[0].Aclid)
+		if err != nil {
+			continue
+		}
+		result = append(result, acl...)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("Instance %s has no NICs in a network with an ACL", vm.Name)
+	}
+
+	return result, nil
 }
@@ -17,9 +17,7 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
-	"os"
 
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
@@ -33,6 +31,8 @@ func newACLListCmd() *cobra.Command {
 		Short: "List ACLs",
 		PreRun: func(cmd *cobra.Command, args []string) {
 			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("columns", cmd.Flags().Lookup("columns"))
+			viper.BindPFlag("extra-columns", cmd.Flags().Lookup("extra-columns"))
 			viper.BindPFlag("filter", cmd.Flags().Lookup("filter"))
 			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
 			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
@@ -42,26 +42,23 @@ func newACLListCmd() *cobra.Command {
 			viper.BindPFlag("vpc-id", cmd.Flags().Lookup("vpc-id"))
 			viper.BindPFlag("vpc-name", cmd.Flags().Lookup("vpc-name"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runACLListCmd(); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runACLListCmd()
 		},
 	}
 
 	// Add local flags.
 	cmd.Flags().BoolP("reverse-sort", "", false, "reverse sort order")
 	cmd.Flags().BoolP("show-description", "", false, "show ACL description in result")
-	cmd.Flags().StringSliceP("filter", "f", nil, "filter results (supports regex)")
-	cmd.Flags().StringP("output", "o", "table", "specify output type")
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
-	cmd.Flags().StringP("sort-by", "s", "vpcname", "field to sort by")
+	cmd.Flags().StringSliceP("columns", "", nil, "fields to show in result, replacing the default fields")
+	cmd.Flags().StringSliceP("extra-columns", "", nil, "additional fields to show in result")
+	cmd.Flags().StringSliceP("filter", "f", nil, "filter results, e.g. \"name=^web\", \"memory>8192\" or \"ipaddress in 10.1.0.0/16\"")
+	cmd.Flags().StringP("output", "o", "table", "specify output type (csv, custom-columns=..., go-template=..., json, table, yaml)")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
+	cmd.Flags().StringP("sort-by", "s", "vpcname", "field(s) to sort by, e.g. \"zonename,name\"")
 	cmd.Flags().StringP("vpc-id", "", "", "specify VPC id")
 	cmd.Flags().StringP("vpc-name", "", "", "specify VPC name")
 
-	cmd.Flags().MarkHidden("output") // Not in use yet.
-
 	return cmd
 }
 
@@ -71,15 +68,23 @@ func runACLListCmd() error {
 		return err
 	}
 
+	ctx, cancel := newContext()
+	defer cancel()
+
 	if err := validateACLListCmd(cfg); err != nil {
 		return err
 	}
 
-	acls, err := cosmic.ListACLs(cosmic.NewAsyncClients(cfg))
-	if err != nil {
+	// Results of profiles that did not fail are still printed when some profiles fail.
+	failures := &cosmic.ProfileErrors{}
+
+	acls, err := cosmic.ListACLs(ctx, newClients(cfg))
+	if err = failures.Collect(err); err != nil {
+		return err
+	}
+	if err := acls.Sort(cfg.SortBy, cfg.ReverseSort); err != nil {
 		return err
 	}
-	acls.Sort(cfg.SortBy, cfg.ReverseSort)
 
 	// Print output
 	fields := []string{"ID", "Name", "VPCName", "ZoneName"}
@@ -94,14 +99,21 @@ func runACLListCmd() error {
 	if cfg.VPCName != "" {
 		cfg.Filter = []string{fmt.Sprintf("%s=^%s$", "vpcname", cfg.VPCName)}
 	}
-	printResult(cfg.Output, "ACL", cfg.Filter, fields, acls)
+	fields, err = selectFields(cfg, "ACL", fields, acls)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	if err := printResult(cfg.Output, "ACL", cfg.Filter, fields, acls); err != nil {
+		return err
+	}
+
+	return failures.ErrorOrNil()
 }
 
 func validateACLListCmd(cfg *config.Config) error {
 	if cfg.VPCID != "" && cfg.VPCName != "" {
-		return errors.New("Cannot specify --vpc-id and --vpc-name together")
+		return &invalidInputError{"Cannot specify --vpc-id and --vpc-name together"}
 	}
 
 	return nil
@@ -17,9 +17,6 @@
 package cmd
 
 import (
-	"errors"
-	"os"
-
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
 	"github.com/spf13/cobra"
@@ -34,6 +31,8 @@ func newACLRuleListCmd() *cobra.Command {
 			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
 			viper.BindPFlag("acl-id", cmd.Flags().Lookup("acl-id"))
 			viper.BindPFlag("acl-name", cmd.Flags().Lookup("acl-name"))
+			viper.BindPFlag("columns", cmd.Flags().Lookup("columns"))
+			viper.BindPFlag("extra-columns", cmd.Flags().Lookup("extra-columns"))
 			viper.BindPFlag("filter", cmd.Flags().Lookup("filter"))
 			viper.BindPFlag("instance-id", cmd.Flags().Lookup("instance-id"))
 			viper.BindPFlag("instance-name", cmd.Flags().Lookup("instance-name"))
@@ -48,11 +47,8 @@ func newACLRuleListCmd() *cobra.Command {
 			viper.BindPFlag("show-rule-number", cmd.Flags().Lookup("show-rule-number"))
 			viper.BindPFlag("sort-by", cmd.Flags().Lookup("sort-by"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runACLRuleListCmd(); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runACLRuleListCmd()
 		},
 	}
 
@@ -62,18 +58,18 @@ func newACLRuleListCmd() *cobra.Command {
 	cmd.Flags().BoolP("show-acl-name", "", false, "show ACL name in result")
 	cmd.Flags().BoolP("show-id", "", false, "show ACL rule id in result")
 	cmd.Flags().BoolP("show-rule-number", "", false, "show ACL rule number in result")
-	cmd.Flags().StringSliceP("filter", "f", nil, "filter results (supports regex)")
+	cmd.Flags().StringSliceP("columns", "", nil, "fields to show in result, replacing the default fields")
+	cmd.Flags().StringSliceP("extra-columns", "", nil, "additional fields to show in result")
+	cmd.Flags().StringSliceP("filter", "f", nil, "filter results, e.g. \"name=^web\", \"memory>8192\" or \"ipaddress in 10.1.0.0/16\"")
 	cmd.Flags().StringP("acl-id", "", "", "specify ACL id")
 	cmd.Flags().StringP("acl-name", "", "", "specify ACL name")
 	cmd.Flags().StringP("instance-id", "", "", "specify instance id")
 	cmd.Flags().StringP("instance-name", "", "", "specify instance name")
 	cmd.Flags().StringP("network-id", "", "", "specify network id")
 	cmd.Flags().StringP("network-name", "", "", "specify network name")
-	cmd.Flags().StringP("output", "o", "table", "specify output type")
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
-	cmd.Flags().StringP("sort-by", "s", "number", "field to sort by")
-
-	cmd.Flags().MarkHidden("output") // Not in use yet.
+	cmd.Flags().StringP("output", "o", "table", "specify output type (csv, custom-columns=..., go-template=..., json, table, yaml)")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
+	cmd.Flags().StringP("sort-by", "s", "number", "field(s) to sort by, e.g. \"zonename,name\"")
 
 	return cmd
 }
@@ -84,12 +80,18 @@ func runACLRuleListCmd() error {
 		return err
 	}
 
+	ctx, cancel := newContext()
+	defer cancel()
+
 	if err := validateACLRuleListCmd(cfg); err != nil {
 		return err
 	}
 
+	// Results of profiles that did not fail are still printed when some profiles fail.
+	failures := &cosmic.ProfileErrors{}
+
 	// Get ACLs based on options
-	acls, err := getACL(cfg)
+	acls, err := getACL(ctx, cfg, failures)
 	if err != nil {
 		return err
 	}
@@ -98,8 +100,8 @@ func runACLRuleListCmd() error {
 	// We loop over acls because if we provided an instance name of id, it may have multiple NICs/ACLs.
 	rules := cosmic.ACLRules{}
 	for _, acl := range acls {
-		r, err := cosmic.ListACLRules(cosmic.NewAsyncClients(cfg), acl.Id)
-		if err != nil {
+		r, err := cosmic.ListACLRules(ctx, newClients(cfg), acl.Id)
+		if err = failures.Collect(err); err != nil {
 			return err
 		}
 		for _, v := range r {
@@ -110,7 +112,9 @@ func runACLRuleListCmd() error {
 			})
 		}
 	}
-	rules.Sort(cfg.SortBy, cfg.ReverseSort)
+	if err := rules.Sort(cfg.SortBy, cfg.ReverseSort); err != nil {
+		return err
+	}
 
 	// Print output
 	fields := []string{"Action", "CidrList", "EndPort", "Icmpcode", "Icmptype", "Protocol", "StartPort", "TrafficType"}
@@ -126,43 +130,50 @@ func runACLRuleListCmd() error {
 	if cfg.ShowRuleNumber {
 		fields = append(fields, "Number")
 	}
-	printResult(cfg.Output, "ACL rule", cfg.Filter, fields, rules)
+	fields, err = selectFields(cfg, "ACL rule", fields, rules)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	if err := printResult(cfg.Output, "ACL rule", cfg.Filter, fields, rules); err != nil {
+		return err
+	}
+
+	return failures.ErrorOrNil()
 }
 
 func validateACLRuleListCmd(cfg *config.Config) error {
 	switch {
 	case cfg.ACLID != "" && cfg.ACLName != "":
-		return errors.New("Cannot specify --acl-id and --acl-name together")
+		return &invalidInputError{"Cannot specify --acl-id and --acl-name together"}
 	case cfg.ACLID != "" && cfg.InstanceID != "":
-		return errors.New("Cannot specify --acl-id and --instance-id together")
+		return &invalidInputError{"Cannot specify --acl-id and --instance-id together"}
 	case cfg.ACLID != "" && cfg.InstanceName != "":
-		return errors.New("Cannot specify --acl-id and --instance-name together")
+		return &invalidInputError{"Cannot specify --acl-id and --instance-name together"}
 	case cfg.ACLID != "" && cfg.NetworkID != "":
-		return errors.New("Cannot specify --acl-id and --network-id together")
+		return &invalidInputError{"Cannot specify --acl-id and --network-id together"}
 	case cfg.ACLID != "" && cfg.NetworkName != "":
-		return errors.New("Cannot specify --acl-id and --network-name together")
+		return &invalidInputError{"Cannot specify --acl-id and --network-name together"}
 	case cfg.ACLName != "" && cfg.InstanceID != "":
-		return errors.New("Cannot specify --acl-name and --instance-id together")
+		return &invalidInputError{"Cannot specify --acl-name and --instance-id together"}
 	case cfg.ACLName != "" && cfg.InstanceName != "":
-		return errors.New("Cannot specify --acl-name and --instance-name together")
+		return &invalidInputError{"Cannot specify --acl-name and --instance-name together"}
 	case cfg.ACLName != "" && cfg.NetworkID != "":
-		return errors.New("Cannot specify --acl-name and --network-id together")
+		return &invalidInputError{"Cannot specify --acl-name and --network-id together"}
 	case cfg.ACLName != "" && cfg.NetworkName != "":
-		return errors.New("Cannot specify --acl-name and --network-name together")
+		return &invalidInputError{"Cannot specify --acl-name and --network-name together"}
 	case cfg.InstanceID != "" && cfg.InstanceName != "":
-		return errors.New("Cannot specify --instance-id and --instance-name together")
+		return &invalidInputError{"Cannot specify --instance-id and --instance-name together"}
 	case cfg.InstanceID != "" && cfg.NetworkID != "":
-		return errors.New("Cannot specify --instance-id and --network-id together")
+		return &invalidInputError{"Cannot specify --instance-id and --network-id together"}
 	case cfg.InstanceID != "" && cfg.NetworkName != "":
-		return errors.New("Cannot specify --instance-id and --network-name together")
+		return &invalidInputError{"Cannot specify --instance-id and --network-name together"}
 	case cfg.InstanceName != "" && cfg.NetworkID != "":
-		return errors.New("Cannot specify --instance-name and --network-id together")
+		return &invalidInputError{"Cannot specify --instance-name and --network-id together"}
 	case cfg.InstanceName != "" && cfg.NetworkName != "":
-		return errors.New("Cannot specify --instance-name and --network-name together")
+		return &invalidInputError{"Cannot specify --instance-name and --network-name together"}
 	case cfg.NetworkID != "" && cfg.NetworkName != "":
-		return errors.New("Cannot specify --network-id and --network-name together")
+		return &invalidInputError{"Cannot specify --network-id and --network-name together"}
 	}
 
 	if cfg.ACLID == "" &&
@@ -171,9 +182,7 @@ func validateACLRuleListCmd(cfg *config.Config) error {
 		cfg.InstanceName == "" &&
 		cfg.NetworkID == "" &&
 		cfg.NetworkName == "" {
-		cmd := newACLRuleListCmd()
-		cmd.Help()
-		os.Exit(0)
+		return errHelp
 	}
 
 	return nil
@@ -0,0 +1,50 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"testing"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+)
+
+func TestInstanceACLs(t *testing.T) {
+	acls := cosmic.ACLs{&cosmic.ACL{NetworkACLList: &gocosmic.NetworkACLList{Id: "acl1", Name: "web"}}}
+	nets := cosmic.Networks{
+		&cosmic.Network{Network: &gocosmic.Network{Id: "net1", Aclid: "acl1"}},
+		&cosmic.Network{Network: &gocosmic.Network{Id: "net2"}},
+		&cosmic.Network{Network: &gocosmic.Network{Id: "net3", Aclid: "acl1"}},
+	}
+	vm := func(networks ...string) *cosmic.VirtualMachine {
+		v := &cosmic.VirtualMachine{VirtualMachine: &gocosmic.VirtualMachine{Name: "web1"}}
+		for _, n := range networks {
+			v.Nic = append(v.Nic, gocosmic.Nic{Networkid: n})
+		}
+		return v
+	}
+
+	// NICs in a network without an ACL are skipped and ACLs are only returned once.
+	got, err := instanceACLs(vm("net2", "net1", "net3"), acls, nets)
+	if err != nil || len(got) != 1 || got[0].Id != "acl1" {
+		t.Errorf("instanceACLs() = %v, %v, want only acl1", got, err)
+	}
+
+	if _, err := instanceACLs(vm("net2"), acls, nets); err == nil {
+		t.Errorf("instanceACLs() returned no error for an instance without ACLs")
+	}
+}
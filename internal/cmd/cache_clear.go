@@ -0,0 +1,67 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newCacheClearCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Clear cached API responses",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheClearCmd()
+		},
+	}
+
+	// Add local flags.
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to clear the cache of, defaults to all profiles")
+
+	return cmd
+}
+
+func runCacheClearCmd() error {
+	// The config isn't loaded as clearing the cache should work without a valid config.
+	profiles := []string{}
+	if p := viper.GetString("profile"); p != "" {
+		profiles = strings.Split(p, ",")
+	}
+
+	dir, err := config.CacheDir()
+	if err != nil {
+		return fmt.Errorf("Error clearing cache: %s", err)
+	}
+
+	if err := cosmic.ClearCache(dir, profiles...); err != nil {
+		return fmt.Errorf("Error clearing cache: %s", err)
+	}
+
+	fmt.Println("Cache cleared.")
+
+	return nil
+}
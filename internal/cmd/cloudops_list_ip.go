@@ -19,7 +19,6 @@ package cmd
 import (
 	"fmt"
 	"net"
-	"os"
 
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
@@ -33,24 +32,23 @@ func newCloudOpsListIPCmd() *cobra.Command {
 		Short: "List IP details",
 		PreRun: func(cmd *cobra.Command, args []string) {
 			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
-			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
+			viper.BindPFlag("columns", cmd.Flags().Lookup("columns"))
+			viper.BindPFlag("extra-columns", cmd.Flags().Lookup("extra-columns"))
 			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
 			viper.BindPFlag("show-mac-address", cmd.Flags().Lookup("show-mac-address"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runCloudOpsListIPCmd(args); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCloudOpsListIPCmd(args)
 		},
 	}
 
 	// Add local flags.
 	cmd.Flags().BoolP("show-mac-address", "", false, "show MAC address in result")
-	cmd.Flags().StringP("output", "o", "table", "specify output type")
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
-
-	cmd.Flags().MarkHidden("output") // Not in use yet.
+	cmd.Flags().StringSliceP("columns", "", nil, "fields to show in result, replacing the default fields")
+	cmd.Flags().StringSliceP("extra-columns", "", nil, "additional fields to show in result")
+	cmd.Flags().StringP("output", "o", "table", "specify output type (csv, custom-columns=..., go-template=..., json, table, yaml)")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
 
 	return cmd
 }
@@ -61,6 +59,9 @@ func runCloudOpsListIPCmd(args []string) error {
 		return err
 	}
 
+	ctx, cancel := newContext()
+	defer cancel()
+
 	// Validate args.
 	ip, err := validateCloudOpsListIPArgs(args)
 	if err != nil {
@@ -70,8 +71,11 @@ func runCloudOpsListIPCmd(args []string) error {
 	// Filter results so we only return the IP we're looking for.
 	cfg.Filter = []string{fmt.Sprintf("%s=^%s$", "ipaddress", ip)}
 
-	ips, err := cosmic.ListIP(cosmic.NewAsyncClients(cfg), ip)
-	if err != nil {
+	// Results of profiles that did not fail are still printed when some profiles fail.
+	failures := &cosmic.ProfileErrors{}
+
+	ips, err := cosmic.ListIP(ctx, newClients(cfg), ip)
+	if err = failures.Collect(err); err != nil {
 		return err
 	}
 
@@ -99,21 +103,26 @@ func runCloudOpsListIPCmd(args []string) error {
 		fields = append(fields, "VirtualMachineName")
 	}
 
-	printResult(cfg.Output, "IP Addresses", cfg.Filter, fields, ips)
+	fields, err = selectFields(cfg, "IP Addresses", fields, ips)
+	if err != nil {
+		return err
+	}
+
+	if err := printResult(cfg.Output, "IP Addresses", cfg.Filter, fields, ips); err != nil {
+		return err
+	}
 
-	return nil
+	return failures.ErrorOrNil()
 }
 
 func validateCloudOpsListIPArgs(args []string) (string, error) {
 	if len(args) != 1 {
-		cmd := newCloudOpsListIPCmd()
-		cmd.Help()
-		os.Exit(0)
+		return "", errHelp
 	}
 
 	ip := net.ParseIP(args[0])
 	if ip == nil {
-		return "", fmt.Errorf("%s is not a valid IP address", args[0])
+		return "", &invalidInputError{fmt.Sprintf("%s is not a valid IP address", args[0])}
 	}
 	return ip.String(), nil
 }
@@ -19,7 +19,6 @@ package cmd
 import (
 	"fmt"
 	"net"
-	"os"
 
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
@@ -33,22 +32,21 @@ func newCloudOpsListMACCmd() *cobra.Command {
 		Short: "List MAC address details",
 		PreRun: func(cmd *cobra.Command, args []string) {
 			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
-			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
+			viper.BindPFlag("columns", cmd.Flags().Lookup("columns"))
+			viper.BindPFlag("extra-columns", cmd.Flags().Lookup("extra-columns"))
 			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runCloudOpsListMACCmd(args); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCloudOpsListMACCmd(args)
 		},
 	}
 
 	// Add local flags.
-	cmd.Flags().StringP("output", "o", "table", "specify output type")
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
-
-	cmd.Flags().MarkHidden("output") // Not in use yet.
+	cmd.Flags().StringSliceP("columns", "", nil, "fields to show in result, replacing the default fields")
+	cmd.Flags().StringSliceP("extra-columns", "", nil, "additional fields to show in result")
+	cmd.Flags().StringP("output", "o", "table", "specify output type (csv, custom-columns=..., go-template=..., json, table, yaml)")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
 
 	return cmd
 }
@@ -59,6 +57,9 @@ func runCloudOpsListMACCmd(args []string) error {
 		return err
 	}
 
+	ctx, cancel := newContext()
+	defer cancel()
+
 	// Validate args.
 	mac, err := validateCloudOpsListMACArgs(args)
 	if err != nil {
@@ -68,8 +69,11 @@ func runCloudOpsListMACCmd(args []string) error {
 	// Filter results so we only return the MAC address we're looking for.
 	cfg.Filter = []string{fmt.Sprintf("%s=^%s$", "macaddress", mac)}
 
-	macs, err := cosmic.ListMAC(cosmic.NewAsyncClients(cfg), mac)
-	if err != nil {
+	// Results of profiles that did not fail are still printed when some profiles fail.
+	failures := &cosmic.ProfileErrors{}
+
+	macs, err := cosmic.ListMAC(ctx, newClients(cfg), mac)
+	if err = failures.Collect(err); err != nil {
 		return err
 	}
 
@@ -97,21 +101,26 @@ func runCloudOpsListMACCmd(args []string) error {
 		fields = append(fields, "VirtualMachineName")
 	}
 
-	printResult(cfg.Output, "MAC Addresses", cfg.Filter, fields, macs)
+	fields, err = selectFields(cfg, "MAC Addresses", fields, macs)
+	if err != nil {
+		return err
+	}
+
+	if err := printResult(cfg.Output, "MAC Addresses", cfg.Filter, fields, macs); err != nil {
+		return err
+	}
 
-	return nil
+	return failures.ErrorOrNil()
 }
 
 func validateCloudOpsListMACArgs(args []string) (string, error) {
 	if len(args) != 1 {
-		cmd := newCloudOpsListMACCmd()
-		cmd.Help()
-		os.Exit(0)
+		return "", errHelp
 	}
 
 	mac, err := net.ParseMAC(args[0])
 	if err != nil {
-		return "", fmt.Errorf("%s is not a valid MAC address", args[0])
+		return "", &invalidInputError{fmt.Sprintf("%s is not a valid MAC address", args[0])}
 	}
 	return mac.String(), nil
 }
@@ -17,12 +17,68 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"regexp"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	h "github.com/shoekstra/cosmic-cli/internal/helper"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
+// Exit codes returned by cosmic-cli.
+const (
+	exitError          = 1   // The command failed.
+	exitPartialFailure = 2   // The command failed using some, but not all, profiles.
+	exitInvalidInput   = 3   // The arguments, flags, filters, columns or sort fields are invalid.
+	exitConfigError    = 4   // The config can't be loaded or a selected profile doesn't exist.
+	exitInterrupted    = 130 // The command was interrupted by the user.
+)
+
+// errHelp is returned by commands called without the arguments or flags they need; cobra prints
+// the help of the command instead of an error and cosmic-cli exits successfully.
+var errHelp = pflag.ErrHelp
+
+// newClients returns the clients used by the commands; tests replace it to use the in-memory API
+// in internal/cosmic/fake.
+var newClients = cosmic.NewAsyncClients
+
+// invalidInputError is returned when a command is called with invalid arguments or flags.
+type invalidInputError struct {
+	message string
+}
+
+// Error returns the invalid input error message.
+func (e *invalidInputError) Error() string {
+	return e.message
+}
+
+// partialError is returned when a command could only complete part of its work.
+type partialError struct {
+	message string
+}
+
+// Error returns the partial error message.
+func (e *partialError) Error() string {
+	return e.message
+}
+
+// interruptedError is returned when the user interrupted a command before it could finish.
+type interruptedError struct {
+	message string
+}
+
+// Error returns the interrupted error message.
+func (e *interruptedError) Error() string {
+	return e.message
+}
+
 // NewCosmicCLICmd creates the `cosmic-cli` command and its subcommands.
 func NewCosmicCLICmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -32,30 +88,127 @@ func NewCosmicCLICmd() *cobra.Command {
 
 It aims to simplify administration of Cosmic Cloud resources by providing single commands for
 actions that may require multiple API calls, whilst running commands against multiple API endpoints
-in parallel.`,
+in parallel.
+
+Exit codes:
+  0    the command succeeded
+  1    the command failed
+  2    the command failed using some, but not all, profiles
+  3    the arguments, flags, filters, columns or sort fields are invalid
+  4    the config can't be loaded or a selected profile doesn't exist
+  130  the command was interrupted`,
 		DisableAutoGenTag: true,
+		// Errors are printed by the caller of Execute, see PrintError and ExitCode.
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if viper.GetString("record") != "" && viper.GetString("replay") != "" {
+				return &invalidInputError{"Cannot specify --record and --replay together"}
+			}
+			return nil
+		},
 	}
 
+	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return &invalidInputError{fmt.Sprintf("%s\nRun '%s --help' for usage.", err, c.CommandPath())}
+	})
+
+	// Add global flags; these are bound once here as they're shared by all subcommands.
+	cmd.PersistentFlags().StringP("config", "", "", "config file to use instead of searching for one, see $"+config.EnvConfig)
+	cmd.PersistentFlags().DurationP("cache-ttl", "", 0, "time cached API responses are used for, 0 disables caching")
+	cmd.PersistentFlags().BoolP("debug", "", false, "log API calls to stderr or the log file")
+	cmd.PersistentFlags().StringP("log-file", "", "", "file to write debug logging to instead of stderr")
+	cmd.PersistentFlags().BoolP("no-cache", "", false, "don't use or update the cache of API responses")
+	cmd.PersistentFlags().IntP("parallelism", "", 8, "maximum number of profiles to query concurrently")
+	cmd.PersistentFlags().Float64P("rate-limit", "", 10, "maximum number of API requests per second per endpoint, 0 to disable")
+	cmd.PersistentFlags().BoolP("refresh", "", false, "ignore cached API responses, but update the cache")
+	cmd.PersistentFlags().StringP("record", "", "", "record API calls to a file, with credentials redacted, to reproduce problems using --replay")
+	cmd.PersistentFlags().StringP("replay", "", "", "return the API responses recorded using --record instead of calling the API")
+	cmd.PersistentFlags().IntP("retry-attempts", "", 3, "maximum number of attempts of a failed API call, 1 to disable retries")
+	cmd.PersistentFlags().DurationP("retry-backoff", "", 500*time.Millisecond, "delay before retrying a failed API call, doubled for every next retry")
+	cmd.PersistentFlags().Float64P("retry-jitter", "", 0.2, "fraction by which the retry delay is randomised")
+	cmd.PersistentFlags().DurationP("timeout", "", 2*time.Minute, "maximum time the API calls of a profile may take, 0 to disable")
+	cmd.PersistentFlags().BoolP("trace", "", false, "log API calls including request parameters and response bodies")
+	viper.BindPFlag("cache-ttl", cmd.PersistentFlags().Lookup("cache-ttl"))
+	viper.BindPFlag("config", cmd.PersistentFlags().Lookup("config"))
+	viper.BindPFlag("debug", cmd.PersistentFlags().Lookup("debug"))
+	viper.BindPFlag("log-file", cmd.PersistentFlags().Lookup("log-file"))
+	viper.BindPFlag("no-cache", cmd.PersistentFlags().Lookup("no-cache"))
+	viper.BindPFlag("parallelism", cmd.PersistentFlags().Lookup("parallelism"))
+	viper.BindPFlag("rate-limit", cmd.PersistentFlags().Lookup("rate-limit"))
+	viper.BindPFlag("record", cmd.PersistentFlags().Lookup("record"))
+	viper.BindPFlag("refresh", cmd.PersistentFlags().Lookup("refresh"))
+	viper.BindPFlag("replay", cmd.PersistentFlags().Lookup("replay"))
+	viper.BindPFlag("retry-attempts", cmd.PersistentFlags().Lookup("retry-attempts"))
+	viper.BindPFlag("retry-backoff", cmd.PersistentFlags().Lookup("retry-backoff"))
+	viper.BindPFlag("retry-jitter", cmd.PersistentFlags().Lookup("retry-jitter"))
+	viper.BindPFlag("timeout", cmd.PersistentFlags().Lookup("timeout"))
+	viper.BindPFlag("trace", cmd.PersistentFlags().Lookup("trace"))
+
 	// Add subcommands.
 	cmd.AddCommand(newDocsCmd())
 	cmd.AddCommand(newVersionCmd())
 
 	// Add subgroups.
 	cmd.AddCommand(newACLCmd())
+	cmd.AddCommand(newCacheCmd())
 	cmd.AddCommand(newCloudOpsCmd())
+	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newDevCmd())
 	cmd.AddCommand(newInstanceCmd())
 	cmd.AddCommand(newVPCCmd())
 
 	return cmd
 }
 
-// printErr prints the error after santizing the output.
-func printErr(err error) {
-	s := err.Error()
-	reapi := regexp.MustCompile(`apiKey=([aA0-zZ9%-]+)`)
-	s = fmt.Sprint(reapi.ReplaceAllString(s, "apiKey=**redacted**"))
-	resig := regexp.MustCompile(`signature=([aA0-zZ9%-]+):`)
-	s = fmt.Sprint(resig.ReplaceAllString(s, "signature=**redacted**:"))
+// ExitCode returns the exit code to use for an error returned by the `cosmic-cli` command.
+func ExitCode(err error) int {
+	switch e := err.(type) {
+	case *cosmic.ProfileErrors:
+		if e.Partial() {
+			return exitPartialFailure
+		}
+	case *partialError:
+		return exitPartialFailure
+	case *invalidInputError, *cosmic.InvalidSortFieldError, *filterError:
+		return exitInvalidInput
+	case *config.LoadError, *config.UnknownProfileError, *config.DuplicateProfileError, *config.SelectionError:
+		return exitConfigError
+	case *interruptedError:
+		return exitInterrupted
+	}
+
+	return exitError
+}
+
+// newContext returns a context that is cancelled when the user interrupts the command. Only the
+// first interrupt is caught, a second interrupt terminates cosmic-cli immediately.
+func newContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
 
-	fmt.Println(s)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		defer signal.Stop(c)
+
+		select {
+		case <-c:
+			fmt.Fprintln(os.Stderr, "Interrupted, waiting for running API calls to be cancelled ...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// PrintError prints an error returned by the `cosmic-cli` command to stderr.
+func PrintError(err error) {
+	printErr(err)
+}
+
+// printErr prints the error to stderr after santizing the output.
+func printErr(err error) {
+	fmt.Fprintln(os.Stderr, h.Redact(err.Error()))
 }
@@ -18,12 +18,69 @@ package cmd
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	"github.com/spf13/viper"
 )
 
-func ExampleprintErr() {
-	err := errors.New("Error returned using profile \"profile1\": Get https://api.cosmic.local/client/api/?apiKey=jDCMCLD8GGeSupR8rFyBRBRKX3AffGKVtycc6B6hjjFNb5D4-ThsU-KrnVJKxzBccTKLx2qArrymxT4xDevr6J&command=listVirtualMachines&response=json&signature=nx963U5Qv08Wm5ey2nRV0U%2B02m4%3D: dial tcp: lookup api.cosmic.local: no such host")
+func TestPrintErr(t *testing.T) {
+	// printErr prints to os.Stderr, so it's replaced while it runs.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	err = errors.New("Error returned using profile \"profile1\": Get https://api.cosmic.local/client/api/?apiKey=jDCMCLD8GGeSupR8rFyBRBRKX3AffGKVtycc6B6hjjFNb5D4-ThsU-KrnVJKxzBccTKLx2qArrymxT4xDevr6J&command=listVirtualMachines&response=json&signature=nx963U5Qv08Wm5ey2nRV0U%2B02m4%3D: dial tcp: lookup api.cosmic.local: no such host")
 	printErr(err)
+	w.Close()
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Error returned using profile \"profile1\": Get https://api.cosmic.local/client/api/?apiKey=**redacted**&command=listVirtualMachines&response=json&signature=**redacted**: dial tcp: lookup api.cosmic.local: no such host\n"
+	if string(b) != want {
+		t.Errorf("printErr() printed %q, want %q", b, want)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		args []string
+		err  error
+		want int
+	}{
+		{err: errors.New("failed"), want: exitError},
+		{err: &partialError{"1 of 2 profiles failed"}, want: exitPartialFailure},
+		{err: &cosmic.InvalidSortFieldError{Field: "nope"}, want: exitInvalidInput},
+		{err: &filterError{filter: "name", err: errors.New("invalid")}, want: exitInvalidInput},
+		{err: &config.UnknownProfileError{Name: "nope"}, want: exitConfigError},
+		{err: &config.DuplicateProfileError{Profile: "ams1", Duplicate: "ams2"}, want: exitConfigError},
+		{err: &config.LoadError{Err: errors.New("not found")}, want: exitConfigError},
+		{err: &interruptedError{"Interrupted"}, want: exitInterrupted},
+		{args: []string{"version", "--bogus"}, want: exitInvalidInput},
+		{args: []string{"vpc", "route", "list", "--config", "/nonexistent"}, want: exitConfigError},
+	}
+
+	for _, tt := range tests {
+		err := tt.err
+		if tt.args != nil {
+			cmd := NewCosmicCLICmd()
+			cmd.SetArgs(tt.args)
+			err = cmd.Execute()
+			viper.Reset()
+		}
 
-	// Output:
-	// Error returned using profile "profile1": Get https://api.cosmic.local/client/api/?apiKey=**redacted**&command=listVirtualMachines&response=json&signature=**redacted**: dial tcp: lookup api.cosmic.local: no such host
+		if got := ExitCode(err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", err, got, tt.want)
+		}
+	}
 }
@@ -0,0 +1,149 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+func newConfigInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Add a profile to the config file",
+		Long: `Add a profile to the config file, creating the config file if none exists.
+
+The profile is added to the config file in use, or to ~/.cosmic-cli/config.toml if no config file
+exists. The secret key is not echoed when it's entered on a terminal; a command printing the
+secret key (e.g. "pass show cosmic/ams1") can be configured instead of storing the secret key
+itself in the config file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigInitCmd(os.Stdin)
+		},
+	}
+
+	return cmd
+}
+
+func runConfigInitCmd(in io.Reader) error {
+	file, err := config.InitFile()
+	if err != nil {
+		return err
+	}
+
+	profiles := map[string]config.Profile{}
+	if _, err := os.Stat(file); err == nil {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		profiles = cfg.Profiles
+	}
+
+	r := bufio.NewReader(in)
+
+	name, err := prompt(r, "Profile name")
+	if err != nil {
+		return err
+	}
+	// Profile names are case-insensitive and read in lower case, so they're written that way too.
+	name = strings.ToLower(name)
+	if _, ok := profiles[name]; ok {
+		return fmt.Errorf("Profile \"%s\" already exists in %s", name, file)
+	}
+
+	p := config.Profile{}
+	if p.APIURL, err = prompt(r, "API URL"); err != nil {
+		return err
+	}
+	if p.APIKey, err = prompt(r, "API key"); err != nil {
+		return err
+	}
+	if p.SecretCommand, err = promptOptional(r, "Command printing the secret key (leave empty to enter the secret key)"); err != nil {
+		return err
+	}
+	if p.SecretCommand == "" {
+		if p.SecretKey, err = promptSecret(r, in, "Secret key"); err != nil {
+			return err
+		}
+	}
+
+	// Check the new profile before adding it, so the config file isn't left with a broken profile.
+	cfg := &config.Config{Profiles: map[string]config.Profile{name: p}}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return errs[0]
+	}
+
+	if err := config.AddProfile(file, name, p); err != nil {
+		return fmt.Errorf("Error adding profile: %s", err)
+	}
+
+	fmt.Printf("Profile \"%s\" added to %s.\n", name, file)
+
+	return nil
+}
+
+// prompt asks for a value until a non-empty value is entered.
+func prompt(r *bufio.Reader, label string) (string, error) {
+	for {
+		value, err := promptOptional(r, label)
+		if err != nil || value != "" {
+			return value, err
+		}
+	}
+}
+
+// promptSecret asks for a value like prompt, without echoing the input when in is a terminal.
+func promptSecret(r *bufio.Reader, in io.Reader, label string) (string, error) {
+	f, ok := in.(*os.File)
+	if !ok || !terminal.IsTerminal(int(f.Fd())) {
+		return prompt(r, label)
+	}
+
+	for {
+		fmt.Printf("%s: ", label)
+		b, err := terminal.ReadPassword(int(f.Fd()))
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("Aborted, no value entered for \"%s\"", label)
+		}
+		if value := strings.TrimSpace(string(b)); value != "" {
+			return value, nil
+		}
+	}
+}
+
+// promptOptional asks for a value once; an error is returned if the input ends before a value is
+// entered.
+func promptOptional(r *bufio.Reader, label string) (string, error) {
+	fmt.Printf("%s: ", label)
+
+	line, err := r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Println()
+		return "", fmt.Errorf("Aborted, no value entered for \"%s\"", label)
+	}
+
+	return strings.TrimSpace(line), nil
+}
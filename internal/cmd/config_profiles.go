@@ -0,0 +1,115 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// profileInfo is a single row of the `config profiles` output.
+type profileInfo struct {
+	Name        string `json:"name"`
+	APIURL      string `json:"apiurl"`
+	Displayname string `json:"displayname,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Zonename    string `json:"zonename"`
+}
+
+func newConfigProfilesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "List configured profiles",
+		PreRun: func(cmd *cobra.Command, args []string) {
+			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("filter", cmd.Flags().Lookup("filter"))
+			viper.BindPFlag("offline", cmd.Flags().Lookup("offline"))
+			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigProfilesCmd()
+		},
+	}
+
+	// Add local flags.
+	cmd.Flags().BoolP("offline", "", false, "don't query the zones of the profiles")
+	cmd.Flags().StringSliceP("filter", "f", nil, "filter results, e.g. \"name=^ams\" or \"environment=production\"")
+	cmd.Flags().StringP("output", "o", "table", "specify output type (csv, custom-columns=..., go-template=..., json, table, yaml)")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
+
+	return cmd
+}
+
+func runConfigProfilesCmd() error {
+	cfg, err := config.New()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	// Profiles that failed are still listed, without their zones.
+	failures := &cosmic.ProfileErrors{}
+
+	profiles, _ := cfg.SelectProfiles()
+	result := []*profileInfo{}
+	for _, name := range profiles {
+		p := cfg.Profiles[name]
+		result = append(result, &profileInfo{
+			Name:        name,
+			APIURL:      p.APIURL,
+			Displayname: p.DisplayName,
+			Environment: p.Environment,
+			Region:      p.Region,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	if !viper.GetBool("offline") {
+		zones, err := cosmic.ListZones(ctx, newClients(cfg))
+		if pe, ok := err.(*cosmic.ProfileErrors); ok {
+			failures = pe
+		} else if err != nil {
+			return err
+		}
+
+		for _, p := range result {
+			names := []string{}
+			for _, z := range zones {
+				if z.Profile == p.Name {
+					names = append(names, z.Name)
+				}
+			}
+			sort.Strings(names)
+			p.Zonename = strings.Join(names, ",")
+		}
+	}
+
+	if err := printResult(cfg.Output, "profile", cfg.Filter, []string{"Name", "APIURL", "DisplayName", "Environment", "Region", "ZoneName"}, result); err != nil {
+		return err
+	}
+
+	return failures.ErrorOrNil()
+}
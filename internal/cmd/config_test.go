@@ -0,0 +1,122 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/spf13/viper"
+)
+
+func Example_runConfigTestCmd() {
+	// A local stub of the API, that only accepts the API key "valid".
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("apiKey") != "valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"listzonesresponse":{"errorcode":401,"errortext":"unable to verify user credentials"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"listzonesresponse":{"count":2,"zone":[{"id":"1","name":"ams1"},{"id":"2","name":"ams2"}]}}`)
+	}))
+	defer ts.Close()
+
+	dir, _ := ioutil.TempDir("", "cosmic-cli-config")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.toml")
+	ioutil.WriteFile(file, []byte(fmt.Sprintf(`
+[profiles.ams]
+api_url = "%s"
+api_key = "valid"
+secret_key = "secret"
+
+[profiles.fra]
+api_url = "%s"
+api_key = "invalid"
+secret_key = "secret"
+`, ts.URL, ts.URL)), 0600)
+
+	viper.Set("config", file)
+	defer viper.Reset()
+
+	err := runConfigTestCmd()
+	fmt.Println(err, ExitCode(err))
+
+	// Output:
+	// ams: OK, 2 zone(s)
+	// fra: FAILED, Cosmic API error 401 (CSExceptionErrorCode: 0): unable to verify user credentials
+	// 1 of 2 profiles failed 2
+}
+
+func TestUnreachableProfiles(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	profiles := map[string]config.Profile{
+		"ams1": {APIURL: ts.URL, InsecureSkipVerify: true},
+		"ams2": {APIURL: ts.URL},
+	}
+
+	errs := unreachableProfiles(context.Background(), profiles)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "\"ams2\"") {
+		t.Errorf("unreachableProfiles() returned %v, want only the untrusted ams2", errs)
+	}
+}
+
+func TestRunConfigInitCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmic-cli-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.toml")
+	viper.Set("config", file)
+	defer viper.Reset()
+
+	// The prompts are printed to os.Stdout, so it's discarded while the command runs.
+	stdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+	defer func() { os.Stdout = stdout }()
+
+	in := "Ams1\nhttps://ams1.cosmic.local/client/api\nkey\n\n\x01s\"ecret\n"
+	if err := runConfigInitCmd(strings.NewReader(in)); err != nil {
+		t.Fatalf("runConfigInitCmd() returned an error: %s", err)
+	}
+
+	cfg, err := config.LoadFile(file)
+	if err != nil {
+		t.Fatalf("LoadFile() returned an error: %s", err)
+	}
+	if p, ok := cfg.Profiles["ams1"]; !ok || p.SecretKey != "\x01s\"ecret" {
+		t.Errorf("runConfigInitCmd() added profiles %+v, want ams1", cfg.Profiles)
+	}
+
+	// The name is case-insensitive, so adding it again fails.
+	if err := runConfigInitCmd(strings.NewReader("AMS1\n")); err == nil {
+		t.Errorf("runConfigInitCmd() returned no error for an existing profile")
+	}
+}
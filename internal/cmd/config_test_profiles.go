@@ -0,0 +1,102 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	h "github.com/shoekstra/cosmic-cli/internal/helper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newConfigTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Test the credentials of profiles",
+		Long: `Test the credentials of profiles by listing their zones, a cheap call that needs valid
+credentials. Cached responses are never used.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigTestCmd()
+		},
+	}
+
+	// Add local flags.
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
+
+	return cmd
+}
+
+func runConfigTestCmd() error {
+	cfg, err := config.New()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	// A cached response would hide invalid credentials.
+	cfg.NoCache = true
+
+	zones, err := cosmic.ListZones(ctx, newClients(cfg))
+	failures, ok := err.(*cosmic.ProfileErrors)
+	if err != nil && !ok {
+		return err
+	}
+
+	failed := map[string]error{}
+	if failures != nil {
+		for _, e := range failures.Errors {
+			failed[e.Profile] = e.Err
+		}
+	}
+
+	profiles, _ := cfg.SelectProfiles()
+	sort.Strings(profiles)
+
+	for _, p := range profiles {
+		if err, ok := failed[p]; ok {
+			fmt.Printf("%s: FAILED, %s\n", p, h.Redact(err.Error()))
+			continue
+		}
+
+		n := 0
+		for _, z := range zones {
+			if z.Profile == p {
+				n++
+			}
+		}
+		fmt.Printf("%s: OK, %d zone(s)\n", p, n)
+	}
+
+	if len(failed) > 0 {
+		if len(failed) < len(profiles) {
+			return &partialError{fmt.Sprintf("%d of %d profiles failed", len(failed), len(profiles))}
+		}
+		return fmt.Errorf("All %d profiles failed", len(profiles))
+	}
+
+	return nil
+}
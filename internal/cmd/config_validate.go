@@ -0,0 +1,163 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// reachableTimeout is the time an API URL has to respond before it is reported as unreachable.
+const reachableTimeout = 10 * time.Second
+
+func newConfigValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the config for problems",
+		Long: `Check the config for problems and report all of them, instead of stopping at the first one.
+
+Profiles are checked for missing or invalid settings, unknown settings and duplicates; groups and
+default_profiles are checked for profiles that don't exist. Unless --offline is used, the API URL
+of every profile is checked to be reachable.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("offline", cmd.Flags().Lookup("offline"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigValidateCmd()
+		},
+	}
+
+	// Add local flags.
+	cmd.Flags().BoolP("offline", "", false, "don't check that the API URLs are reachable")
+
+	return cmd
+}
+
+func runConfigValidateCmd() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	problems := cfg.Validate()
+	if !viper.GetBool("offline") {
+		problems = append(problems, unreachableProfiles(ctx, cfg.Profiles)...)
+	}
+
+	file := cfg.File()
+	if file == "" {
+		file = "environment"
+	}
+
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("Found %d problem(s) in config %s", len(problems), file)
+	}
+
+	fmt.Printf("Config %s is valid.\n", file)
+
+	return nil
+}
+
+// unreachableProfiles returns an error for each profile of which the API URL doesn't respond. Any
+// HTTP response, including errors, means the URL is reachable. The TLS and proxy settings of the
+// profile are used; invalid URLs and settings are skipped as they are reported by config.Validate.
+func unreachableProfiles(ctx context.Context, profiles map[string]config.Profile) []error {
+	names := []string{}
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, len(names))
+	wg := sync.WaitGroup{}
+
+	for i, name := range names {
+		u, err := url.Parse(profiles[name].APIURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+
+		client, err := profileHTTPClient(profiles[name])
+		if err != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+			if err != nil {
+				errs[i] = fmt.Errorf("Profile \"%s\": api_url \"%s\" is unreachable: %s", name, u, err)
+				return
+			}
+
+			resp, err := client.Do(req.WithContext(ctx))
+			if err != nil {
+				errs[i] = fmt.Errorf("Profile \"%s\": api_url \"%s\" is unreachable: %s", name, u, err)
+				return
+			}
+			resp.Body.Close()
+		}(i, name)
+	}
+	wg.Wait()
+
+	result := []error{}
+	for _, err := range errs {
+		if err != nil {
+			result = append(result, err)
+		}
+	}
+
+	return result
+}
+
+// profileHTTPClient returns a HTTP client using the TLS and proxy settings of p, like the clients
+// used for API calls.
+func profileHTTPClient(p config.Profile) (*http.Client, error) {
+	tlsConfig, err := p.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := p.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{Timeout: reachableTimeout, Transport: transport}, nil
+}
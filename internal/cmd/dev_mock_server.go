@@ -0,0 +1,92 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/shoekstra/cosmic-cli/internal/mockserver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newDevMockServerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mock-server FIXTURE",
+		Short: "Serve a mock Cosmic API using resources from a YAML fixture",
+		Long: `Serve a mock Cosmic API using resources from a YAML fixture, e.g. to run cosmic-cli in CI or
+for a demo without a real Cosmic API.
+
+The mock API checks the API key and request signature using the api_key and secret_key of the
+fixture, implements the API calls used by cosmic-cli and runs async jobs that can be polled. Add a
+profile using the URL that is printed on start up and the credentials of the fixture to use it.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("listen", cmd.Flags().Lookup("listen"))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDevMockServerCmd(args)
+		},
+	}
+
+	// Add local flags.
+	cmd.Flags().StringP("listen", "", "127.0.0.1:8080", "address to listen on")
+
+	return cmd
+}
+
+func runDevMockServerCmd(args []string) error {
+	if len(args) == 0 {
+		return errHelp
+	}
+	if len(args) > 1 {
+		return &invalidInputError{"Incorrect number of parameters passed, this command expects a single fixture file"}
+	}
+
+	f, err := mockserver.LoadFixture(args[0])
+	if err != nil {
+		return &invalidInputError{err.Error()}
+	}
+
+	l, err := net.Listen("tcp", viper.GetString("listen"))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	srv := &http.Server{Handler: mockserver.New(f)}
+	go func() {
+		<-ctx.Done()
+		sctx, scancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer scancel()
+		srv.Shutdown(sctx)
+	}()
+
+	fmt.Printf("Serving the mock Cosmic API at http://%s/client/api, press Ctrl+C to stop.\n", l.Addr())
+
+	if err := srv.Serve(l); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
@@ -17,8 +17,6 @@
 package cmd
 
 import (
-	"os"
-
 	"github.com/shoekstra/cosmic-cli/internal/docs"
 	"github.com/spf13/cobra"
 )
@@ -28,11 +26,8 @@ func newDocsCmd() *cobra.Command {
 		Use:    "docs",
 		Short:  "Generate documentation as markdown",
 		Hidden: true,
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runDocsCmd(args); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDocsCmd(args)
 		},
 	}
 
@@ -0,0 +1,319 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	h "github.com/shoekstra/cosmic-cli/internal/helper"
+)
+
+// Filters are passed as expressions in the form of "field op value", where op is one of:
+//
+//	=   value is a regular expression that should match the field
+//	~   same as "="
+//	!~  value is a regular expression that should not match the field
+//	==  value should equal the field
+//	!=  value should not equal the field
+//	<   field should be a number smaller than value (also "<=", ">" and ">=")
+//	in  field should equal one of the values in a "(a,b)" list; values that are CIDRs match
+//	    any field containing an IP address in that network
+//
+// Fields can be nested paths such as "nic.*.ipaddress" or "tags.env", in which case the filter
+// matches if any of the values matches.
+//
+// Expressions can be combined with "&&" and "||", where "&&" binds tighter than "||". When more
+// than one filter is passed, all filters must match.
+var filterOperators = []string{"==", "!=", "!~", "<=", ">=", "=", "~", "<", ">"}
+
+// filterCondition represents a single "field op value" expression.
+type filterCondition struct {
+	field  string
+	op     string
+	values []string
+	re     *regexp.Regexp
+	num    float64
+	nets   []*net.IPNet
+}
+
+// filterError is returned for a filter that can't be parsed.
+type filterError struct {
+	filter string
+	err    error
+}
+
+// Error returns the filter error message.
+func (e *filterError) Error() string {
+	return fmt.Sprintf("Invalid filter \"%s\": %s", e.filter, e.err)
+}
+
+// filterExpr represents a parsed filter; it matches if all conditions in any of its groups match.
+type filterExpr [][]*filterCondition
+
+// parseFilters parses filters and validates the fields they use against t, the element type of
+// the result they are applied to.
+func parseFilters(filters []string, cosmicType string, t reflect.Type) ([]filterExpr, error) {
+	exprs := []filterExpr{}
+	for _, f := range joinFilterArgs(filters) {
+		expr, err := parseFilter(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range expr {
+			for _, c := range group {
+				if !h.HasFieldPath(t, c.field) {
+					return nil, &filterError{filter: f, err: fmt.Errorf("no such field \"%s\" exists for %s", c.field, cosmicType)}
+				}
+			}
+		}
+		exprs = append(exprs, expr)
+	}
+
+	return exprs, nil
+}
+
+// joinFilterArgs joins filters that were split on a comma when they were passed to a string slice
+// flag: fragments inside a "(a,b)" list and fragments that aren't a filter on their own, such as
+// the "3}" of "name=a{1,3}", are joined to the filter before them.
+func joinFilterArgs(filters []string) []string {
+	result := []string{}
+	open := false
+	for _, f := range filters {
+		if open || (len(result) > 0 && !isFilter(f)) {
+			result[len(result)-1] += "," + f
+		} else {
+			result = append(result, f)
+		}
+		open = strings.Count(result[len(result)-1], "(") > strings.Count(result[len(result)-1], ")")
+	}
+
+	return result
+}
+
+// isFilter returns true if s can be parsed as a filter.
+func isFilter(s string) bool {
+	_, err := parseFilter(s)
+	return err == nil
+}
+
+func parseFilter(filter string) (filterExpr, error) {
+	expr := filterExpr{}
+	for _, or := range strings.Split(filter, "||") {
+		group := []*filterCondition{}
+		for _, and := range strings.Split(or, "&&") {
+			c, err := parseFilterCondition(strings.TrimSpace(and))
+			if err != nil {
+				return nil, &filterError{filter: filter, err: err}
+			}
+			group = append(group, c)
+		}
+		expr = append(expr, group)
+	}
+
+	return expr, nil
+}
+
+func parseFilterCondition(s string) (*filterCondition, error) {
+	c := &filterCondition{}
+
+	// The field name ends at the first character that can't be part of a field name.
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '_' || r == '.' || r == '[' || r == ']' || r == '*' ||
+			('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9'))
+	})
+	if i <= 0 {
+		return nil, fmt.Errorf("filters should be in the form of \"field=value\"")
+	}
+	c.field = s[:i]
+	rest := strings.TrimSpace(s[i:])
+
+	if len(rest) > 3 && strings.EqualFold(rest[:3], "in ") {
+		c.op = "in"
+		rest = rest[3:]
+	} else {
+		for _, op := range filterOperators {
+			if strings.HasPrefix(rest, op) {
+				c.op = op
+				rest = rest[len(op):]
+				break
+			}
+		}
+	}
+	if c.op == "" {
+		return nil, fmt.Errorf("unknown operator, filters should be in the form of \"field=value\"")
+	}
+	value := strings.TrimSpace(rest)
+
+	var err error
+	switch c.op {
+	case "=", "~", "!~":
+		c.re, err = regexp.Compile("(?i)" + value)
+		if err != nil {
+			return nil, err
+		}
+	case "<", "<=", ">", ">=":
+		c.num, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("\"%s\" is not a number", value)
+		}
+	case "in":
+		if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+			value = value[1 : len(value)-1]
+		}
+		for _, v := range strings.Split(value, ",") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				return nil, fmt.Errorf("empty value in list")
+			}
+			if _, n, err := net.ParseCIDR(v); err == nil {
+				c.nets = append(c.nets, n)
+				continue
+			}
+			c.values = append(c.values, v)
+		}
+	default:
+		c.values = []string{value}
+	}
+
+	return c, nil
+}
+
+// match returns true if the filtered field of obj satisfies the condition. Fields with multiple
+// values match if any value matches, or for negated operators if no value matches.
+func (c *filterCondition) match(obj interface{}) bool {
+	values, ok := filterValues(obj, c.field)
+	if !ok {
+		return false
+	}
+
+	switch c.op {
+	case "!~":
+		return !c.matchAny(values, "~")
+	case "!=":
+		return !c.matchAny(values, "==")
+	}
+
+	return c.matchAny(values, c.op)
+}
+
+// matchAny returns true if any of values satisfies op.
+func (c *filterCondition) matchAny(values []string, op string) bool {
+	for _, value := range values {
+		if c.matchValue(value, op) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *filterCondition) matchValue(value, op string) bool {
+	switch op {
+	case "=", "~":
+		return c.re.MatchString(value)
+	case "==":
+		return strings.EqualFold(value, c.values[0])
+	case "<", "<=", ">", ">=":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		switch op {
+		case "<":
+			return n < c.num
+		case "<=":
+			return n <= c.num
+		case ">":
+			return n > c.num
+		default:
+			return n >= c.num
+		}
+	case "in":
+		for _, v := range c.values {
+			if strings.EqualFold(value, v) {
+				return true
+			}
+		}
+		if ip := net.ParseIP(value); ip != nil {
+			for _, n := range c.nets {
+				if n.Contains(ip) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// match returns true if obj matches all conditions of any of the groups in the filter.
+func (expr filterExpr) match(obj interface{}) bool {
+	for _, group := range expr {
+		match := true
+		for _, c := range group {
+			if !c.match(obj) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterValues returns the values of the named field of obj formatted as strings.
+func filterValues(obj interface{}, field string) ([]string, bool) {
+	values, ok := h.FieldValues(obj, field)
+	if !ok {
+		return nil, false
+	}
+
+	result := []string{}
+	for _, v := range values {
+		result = append(result, fmt.Sprintf("%v", v))
+	}
+
+	return result, true
+}
+
+func filterOutput(result interface{}, filters []filterExpr) interface{} {
+	if len(filters) == 0 {
+		return result
+	}
+
+	slice := h.InterfaceSlice(result)
+
+	for i := 0; i < len(slice); i++ {
+		for _, f := range filters {
+			if !f.match(slice[i]) {
+				slice = append(slice[:i], slice[i+1:]...)
+				i-- // -1 as the slice just got shorter.
+				break
+			}
+		}
+	}
+
+	return slice
+}
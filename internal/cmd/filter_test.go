@@ -0,0 +1,92 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+)
+
+func TestFilterMatch(t *testing.T) {
+	pgw := &cosmic.PrivateGateway{
+		PrivateGateway: &gocosmic.PrivateGateway{Ipaddress: "10.1.2.3", Vlan: "100", Zonename: "ams1"},
+		Vpcname:        "vpc-prod",
+	}
+
+	tests := []struct {
+		filter string
+		match  bool
+	}{
+		{"vpcname=prod", true},
+		{"vpcname=^prod", false},
+		{"vpcname~PROD$", true},
+		{"vpcname!~prod", false},
+		{"vpcname==vpc-prod", true},
+		{"vpcname==prod", false},
+		{"vpcname!=vpc-test", true},
+		{"vlan>99", true},
+		{"vlan<=99", false},
+		{"zonename in (ams1,rtm1)", true},
+		{"zonename in (rtm1, rtm2)", false},
+		{"ipaddress in 10.1.0.0/16", true},
+		{"ipaddress in (192.168.0.0/16,10.0.0.0/8)", true},
+		{"ipaddress in 10.2.0.0/16", false},
+		{"zonename=rtm1 || zonename=ams1", true},
+		{"zonename=ams1 && vpcname=test", false},
+		{"zonename=rtm1 || zonename=ams1 && vpcname=prod", true},
+		{"nonexistent=.*", false},
+	}
+
+	for _, tt := range tests {
+		expr, err := parseFilter(tt.filter)
+		if err != nil {
+			t.Errorf("parseFilter(%q) returned error: %s", tt.filter, err)
+			continue
+		}
+		if match := expr.match(pgw); match != tt.match {
+			t.Errorf("filter %q: got match %v, want %v", tt.filter, match, tt.match)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, filter := range []string{"name", "=value", "vlan>abc", "name=(", "zonename in ()"} {
+		if _, err := parseFilter(filter); err == nil {
+			t.Errorf("parseFilter(%q) did not return an error", filter)
+		}
+	}
+}
+
+func TestJoinFilterArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"zonename in (ams1", "rtm1)", "name=web"}, []string{"zonename in (ams1,rtm1)", "name=web"}},
+		{[]string{"name=a{1", "3}"}, []string{"name=a{1,3}"}},
+		{[]string{"ipaddress in 10.1.0.0/16", "10.2.0.0/16", "name=web"}, []string{"ipaddress in 10.1.0.0/16,10.2.0.0/16", "name=web"}},
+	}
+
+	for _, tt := range tests {
+		if got := joinFilterArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("joinFilterArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
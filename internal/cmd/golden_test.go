@@ -0,0 +1,252 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic/fake"
+	h "github.com/shoekstra/cosmic-cli/internal/helper"
+	"github.com/shoekstra/cosmic-cli/internal/mockserver"
+	"github.com/spf13/viper"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// goldenConfig is the config used by the golden tests; profile ams1 uses the resources of the
+// mock-server fixture and every API call using profile fra1 fails.
+const goldenConfig = `
+default_profiles = ["ams1"]
+
+[profiles.ams1]
+api_url = "https://ams1.example.com/client/api"
+api_key = "key"
+secret_key = "secret"
+
+[profiles.fra1]
+api_url = "https://fra1.example.com/client/api"
+api_key = "key"
+secret_key = "secret"
+`
+
+// runGolden runs cosmic-cli with args against the fake API of the golden tests and returns its
+// output, followed by the exit code and the error printed to stderr.
+func runGolden(t *testing.T, args []string) string {
+	dir, err := ioutil.TempDir("", "cosmic-cli-golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(file, []byte(goldenConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fixture, err := mockserver.LoadFixture(filepath.Join("..", "mockserver", "testdata", "fixture.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backends := map[string]*fake.Backend{
+		"ams1": fixture.Backend(),
+		"fra1": {Err: errors.New("connection refused")},
+	}
+
+	newClients = func(cfg *config.Config) map[string]*cosmic.Client {
+		profiles, _ := cfg.SelectProfiles()
+		selected := map[string]*fake.Backend{}
+		for _, p := range profiles {
+			selected[p] = backends[p]
+		}
+		return fake.Clients(selected)
+	}
+	defer func() { newClients = cosmic.NewAsyncClients }()
+
+	viper.Reset()
+	defer viper.Reset()
+
+	// Commands print to os.Stdout, so it's replaced while the command runs.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan []byte)
+	go func() {
+		buf := &bytes.Buffer{}
+		io.Copy(buf, r)
+		out <- buf.Bytes()
+	}()
+
+	cmd := NewCosmicCLICmd()
+	cmd.SetArgs(append(args, "--config", file))
+	err = cmd.Execute()
+
+	w.Close()
+	b := <-out
+
+	if err != nil {
+		return fmt.Sprintf("%s[exit code %d] %s\n", b, ExitCode(err), h.Redact(err.Error()))
+	}
+
+	return fmt.Sprintf("%s[exit code 0]\n", b)
+}
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		// Output of every command.
+		{"acl_list", []string{"acl", "list"}},
+		{"acl_list_csv", []string{"acl", "list", "-o", "csv"}},
+		{"acl_list_json", []string{"acl", "list", "-o", "json"}},
+		{"acl_rule_list", []string{"acl", "rule", "list", "--acl-name", "web-acl"}},
+		{"acl_rule_list_csv", []string{"acl", "rule", "list", "--acl-name", "web-acl", "-o", "csv"}},
+		{"acl_rule_list_json", []string{"acl", "rule", "list", "--instance-name", "db1", "-o", "json"}},
+		{"cloudops_list_ip", []string{"cloudops", "list", "ip", "10.100.1.10"}},
+		{"cloudops_list_ip_csv", []string{"cloudops", "list", "ip", "10.100.1.10", "-o", "csv"}},
+		{"cloudops_list_ip_json", []string{"cloudops", "list", "ip", "10.100.1.10", "-o", "json"}},
+		{"cloudops_list_mac", []string{"cloudops", "list", "mac", "02:00:00:00:01:0a"}},
+		{"cloudops_list_mac_csv", []string{"cloudops", "list", "mac", "02:00:00:00:01:0a", "-o", "csv"}},
+		{"cloudops_list_mac_json", []string{"cloudops", "list", "mac", "02:00:00:00:01:0a", "-o", "json"}},
+		{"config_profiles", []string{"config", "profiles", "--offline"}},
+		{"instance_list", []string{"instance", "list"}},
+		{"instance_list_csv", []string{"instance", "list", "-o", "csv"}},
+		{"instance_list_json", []string{"instance", "list", "-o", "json"}},
+		{"vpc_list", []string{"vpc", "list"}},
+		{"vpc_list_csv", []string{"vpc", "list", "-o", "csv"}},
+		{"vpc_list_json", []string{"vpc", "list", "-o", "json"}},
+		{"vpc_pgw_list", []string{"vpc", "pgw", "list"}},
+		{"vpc_pgw_list_csv", []string{"vpc", "pgw", "list", "-o", "csv"}},
+		{"vpc_pgw_list_json", []string{"vpc", "pgw", "list", "-o", "json"}},
+		{"vpc_route_add", []string{"vpc", "route", "add", "10.9.0.0/16,192.168.0.0/16", "via", "172.16.0.254", "--vpc-name", "web"}},
+		{"vpc_route_delete", []string{"vpc", "route", "delete", "cidr=192.168", "--vpc-name", "web"}},
+		{"vpc_route_add_other_profile_down", []string{"vpc", "route", "add", "10.9.0.0/16", "via", "172.16.0.254", "--vpc-name", "web", "-p", "ams1,fra1"}},
+		{"vpc_route_flush", []string{"vpc", "route", "flush", "--vpc-name", "web"}},
+		{"vpc_route_list", []string{"vpc", "route", "list", "--vpc-name", "web"}},
+		{"vpc_route_list_csv", []string{"vpc", "route", "list", "--vpc-name", "web", "-o", "csv"}},
+		{"vpc_route_list_json", []string{"vpc", "route", "list", "--vpc-name", "web", "-o", "json"}},
+		{"version", []string{"version"}},
+
+		// Flag validation and exit codes.
+		{"acl_list_vpc_conflict", []string{"acl", "list", "--vpc-id", "1", "--vpc-name", "web"}},
+		{"acl_rule_list_no_flags", []string{"acl", "rule", "list"}},
+		{"acl_rule_list_conflict", []string{"acl", "rule", "list", "--acl-name", "web-acl", "--network-name", "web-tier"}},
+		{"cloudops_list_ip_invalid", []string{"cloudops", "list", "ip", "10.100.1"}},
+		{"cloudops_list_mac_invalid", []string{"cloudops", "list", "mac", "02:00:00"}},
+		{"instance_list_failed", []string{"instance", "list", "-p", "fra1"}},
+		{"instance_list_invalid_column", []string{"instance", "list", "--columns", "nope"}},
+		{"instance_list_invalid_filter", []string{"instance", "list", "-f", "name"}},
+		{"instance_list_unknown_filter_field", []string{"instance", "list", "-f", "nope=1"}},
+		{"instance_list_invalid_output", []string{"instance", "list", "-o", "xml"}},
+		{"instance_list_invalid_custom_column", []string{"instance", "list", "-o", "custom-columns=X:nope"}},
+		{"instance_list_invalid_template_field", []string{"instance", "list", "-o", "go-template={{.Nope}}"}},
+		{"instance_list_invalid_sort", []string{"instance", "list", "-s", "nope"}},
+		{"instance_list_partial", []string{"instance", "list", "-p", "ams1,fra1"}},
+		{"instance_list_record_replay", []string{"instance", "list", "--record", "a", "--replay", "b"}},
+		{"instance_list_unknown_flag", []string{"instance", "list", "--nope"}},
+		{"instance_list_unknown_profile", []string{"instance", "list", "-p", "nope"}},
+		{"vpc_route_add_invalid_cidr", []string{"vpc", "route", "add", "10.9.0.0", "via", "172.16.0.254", "--vpc-name", "web"}},
+		{"vpc_route_add_vpc_conflict", []string{"vpc", "route", "add", "10.9.0.0/16", "via", "172.16.0.254", "--vpc-id", "1", "--vpc-name", "web"}},
+		{"vpc_route_delete_invalid", []string{"vpc", "route", "delete", "gateway=10.0.0.1", "--vpc-name", "web"}},
+		{"vpc_route_delete_no_value", []string{"vpc", "route", "delete", "cidr", "--vpc-name", "web"}},
+		{"vpc_route_list_no_vpc", []string{"vpc", "route", "list"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runGolden(t, tt.args)
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%s, run \"go test ./internal/cmd -update\" to create it", err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s:\n--- got ---\n%s--- want ---\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestValidateACLRuleListCmd(t *testing.T) {
+	flags := []string{"acl-id", "acl-name", "instance-id", "instance-name", "network-id", "network-name"}
+
+	set := func(cfg *config.Config, name string) {
+		switch name {
+		case "acl-id":
+			cfg.ACLID = "1"
+		case "acl-name":
+			cfg.ACLName = "web-acl"
+		case "instance-id":
+			cfg.InstanceID = "1"
+		case "instance-name":
+			cfg.InstanceName = "web1"
+		case "network-id":
+			cfg.NetworkID = "1"
+		case "network-name":
+			cfg.NetworkName = "web-tier"
+		}
+	}
+
+	// Every pair of flags is mutually exclusive.
+	for i, a := range flags {
+		for _, b := range flags[i+1:] {
+			cfg := &config.Config{}
+			set(cfg, a)
+			set(cfg, b)
+
+			err := validateACLRuleListCmd(cfg)
+			want := fmt.Sprintf("Cannot specify --%s and --%s together", a, b)
+			if err == nil || err.Error() != want || ExitCode(err) != exitInvalidInput {
+				t.Errorf("validateACLRuleListCmd(--%s, --%s) = %v, want %q", a, b, err, want)
+			}
+		}
+	}
+
+	for _, f := range flags {
+		cfg := &config.Config{}
+		set(cfg, f)
+		if err := validateACLRuleListCmd(cfg); err != nil {
+			t.Errorf("validateACLRuleListCmd(--%s) = %v, want nil", f, err)
+		}
+	}
+
+	if err := validateACLRuleListCmd(&config.Config{}); err != errHelp {
+		t.Errorf("validateACLRuleListCmd() = %v, want errHelp", err)
+	}
+}
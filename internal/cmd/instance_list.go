@@ -17,10 +17,11 @@
 package cmd
 
 import (
-	"os"
+	"strings"
 
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	h "github.com/shoekstra/cosmic-cli/internal/helper"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -31,6 +32,8 @@ func newInstanceListCmd() *cobra.Command {
 		Short: "List instances",
 		PreRun: func(cmd *cobra.Command, args []string) {
 			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("columns", cmd.Flags().Lookup("columns"))
+			viper.BindPFlag("extra-columns", cmd.Flags().Lookup("extra-columns"))
 			viper.BindPFlag("filter", cmd.Flags().Lookup("filter"))
 			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
 			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
@@ -43,11 +46,8 @@ func newInstanceListCmd() *cobra.Command {
 			viper.BindPFlag("show-version", cmd.Flags().Lookup("show-version"))
 			viper.BindPFlag("sort-by", cmd.Flags().Lookup("sort-by"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runInstanceListCmd(); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInstanceListCmd()
 		},
 	}
 
@@ -59,12 +59,12 @@ func newInstanceListCmd() *cobra.Command {
 	cmd.Flags().BoolP("show-service-offering", "", false, "show instance service offering in result")
 	cmd.Flags().BoolP("show-template", "", false, "show instance template name in result")
 	cmd.Flags().BoolP("show-version", "", false, "show instance version in result")
-	cmd.Flags().StringSliceP("filter", "f", nil, "filter results (supports regex)")
-	cmd.Flags().StringP("output", "o", "table", "specify output type")
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
-	cmd.Flags().StringP("sort-by", "s", "name", "field to sort by")
-
-	cmd.Flags().MarkHidden("output") // Not in use yet.
+	cmd.Flags().StringSliceP("columns", "", nil, "fields to show in result, replacing the default fields")
+	cmd.Flags().StringSliceP("extra-columns", "", nil, "additional fields to show in result")
+	cmd.Flags().StringSliceP("filter", "f", nil, "filter results, e.g. \"name=^web\", \"memory>8192\" or \"ipaddress in 10.1.0.0/16\"")
+	cmd.Flags().StringP("output", "o", "table", "specify output type (csv, custom-columns=..., go-template=..., json, table, yaml)")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
+	cmd.Flags().StringP("sort-by", "s", "name", "field(s) to sort by, e.g. \"zonename,name\"")
 
 	return cmd
 }
@@ -75,40 +75,55 @@ func runInstanceListCmd() error {
 		return err
 	}
 
-	instances, err := cosmic.ListVMs(cosmic.NewAsyncClients(cfg))
-	if err != nil {
+	ctx, cancel := newContext()
+	defer cancel()
+
+	// Results of profiles that did not fail are still printed when some profiles fail.
+	failures := &cosmic.ProfileErrors{}
+
+	instances, err := cosmic.ListVMs(ctx, newClients(cfg))
+	if err = failures.Collect(err); err != nil {
 		return err
 	}
-	instances.Sort(cfg.SortBy, cfg.ReverseSort)
 
 	if cfg.ShowNetwork {
-		networks, err := cosmic.ListNetworks(cosmic.NewAsyncClients(cfg))
-		if err != nil {
+		networks, err := cosmic.ListNetworks(ctx, newClients(cfg))
+		if err = failures.Collect(err); err != nil {
 			return err
 		}
-		vpcs, err := cosmic.ListVPCs(cosmic.NewAsyncClients(cfg))
-		if err != nil {
+		vpcs, err := cosmic.ListVPCs(ctx, newClients(cfg))
+		if err = failures.Collect(err); err != nil {
 			return err
 		}
 		for _, i := range instances {
-			vpcid := ""
-			for _, n := range networks {
-				if n.Id == i.Nic[0].Networkid {
-					i.Networkname = n.Name
-					vpcid = n.Vpcid
-					break
+			networknames := []string{}
+			vpcnames := []string{}
+			for _, nic := range i.Nic {
+				vpcid := ""
+				for _, n := range networks {
+					if n.Id == nic.Networkid {
+						networknames = append(networknames, n.Name)
+						vpcid = n.Vpcid
+						break
+					}
 				}
-			}
 
-			for _, v := range vpcs {
-				if v.Id == vpcid {
-					i.Vpcname = v.Name
-					break
+				for _, v := range vpcs {
+					if v.Id == vpcid && !h.Contains(vpcnames, v.Name) {
+						vpcnames = append(vpcnames, v.Name)
+						break
+					}
 				}
 			}
+			i.Networkname = strings.Join(networknames, ", ")
+			i.Vpcname = strings.Join(vpcnames, ", ")
 		}
 	}
 
+	if err := instances.Sort(cfg.SortBy, cfg.ReverseSort); err != nil {
+		return err
+	}
+
 	// Print output
 	fields := []string{"Name", "InstanceName", "State", "IPAddress", "ZoneName"}
 	if cfg.ShowID {
@@ -130,7 +145,14 @@ func runInstanceListCmd() error {
 	if cfg.ShowVersion {
 		fields = append(fields, "Version")
 	}
-	printResult(cfg.Output, "instance", cfg.Filter, fields, instances)
+	fields, err = selectFields(cfg, "instance", fields, instances)
+	if err != nil {
+		return err
+	}
+
+	if err := printResult(cfg.Output, "instance", cfg.Filter, fields, instances); err != nil {
+		return err
+	}
 
-	return nil
+	return failures.ErrorOrNil()
 }
@@ -17,104 +17,154 @@
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
-	"regexp"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/shoekstra/cosmic-cli/internal/config"
 	h "github.com/shoekstra/cosmic-cli/internal/helper"
+	yaml "gopkg.in/yaml.v2"
 )
 
-func filterMatch(obj interface{}, filter string) bool {
-	if filter == "" {
-		return true
-	}
+// selectFields returns the fields to print; the fields passed in are sorted, or replaced with the
+// fields set using --columns, and extended with the fields set using --extra-columns. Fields set by
+// the user keep their order and are validated against the element type of result.
+func selectFields(cfg *config.Config, cosmicType string, fields []string, result interface{}) ([]string, error) {
+	t := reflect.TypeOf(result).Elem()
 
-	filterField, filterString := filterSplit(filter)
+	fields = append([]string{}, fields...)
+	sort.Strings(fields)
 
-	value := ""
-	val := reflect.Indirect(reflect.ValueOf(obj))
-	switch {
-	case strings.EqualFold(filterField, "ipaddress"):
-		v := val.FieldByName("Nic")
-		if v.IsValid() {
-			value = fmt.Sprintf("%v", v.Index(0).FieldByName("Ipaddress"))
-			break
+	if len(cfg.Columns) > 0 {
+		if err := validateColumns(t, cosmicType, cfg.Columns); err != nil {
+			return nil, err
 		}
-		fallthrough
-	case strings.EqualFold(filterField, "version"):
-		v := val.FieldByName("Laststartversion")
-		if v.IsValid() {
-			value = fmt.Sprintf("%v", v.Interface())
-			break
-		}
-		fallthrough
-	default:
-		// First try to read the field directly; if it exists set the value and break.
-		fn := strings.Title(strings.ToLower(filterField))
-		valueField := val.FieldByName(fn)
-		if valueField.IsValid() {
-			value = fmt.Sprintf("%v", valueField.Interface())
-			break
+		fields = append([]string{}, cfg.Columns...)
+	}
+
+	if err := validateColumns(t, cosmicType, cfg.ExtraColumns); err != nil {
+		return nil, err
+	}
+	for _, c := range cfg.ExtraColumns {
+		if !h.Contains(fields, c) {
+			fields = append(fields, c)
 		}
+	}
 
-		// If we can't read the field directly, we'll then loop through all field names and set
-		// value if we find a matching field name.
-		for i := 0; i < val.NumField(); i++ {
-			typeField := val.Type().Field(i)
+	return fields, nil
+}
 
-			if strings.EqualFold(filterField, typeField.Name) {
-				valueField := val.Field(i)
-				value = fmt.Sprintf("%v", valueField.Interface())
-				break
-			}
+// validateColumns returns an error for the first column that isn't a field of t.
+func validateColumns(t reflect.Type, cosmicType string, columns []string) error {
+	for _, c := range columns {
+		if !h.HasFieldPath(t, c) {
+			return &invalidInputError{fmt.Sprintf("Invalid column \"%s\", no such field exists for %s", c, cosmicType)}
 		}
+	}
 
-		// At this point we'll assume the field doesn't exist and return 0 matches; if it has
-		// some funky key then probably better to add as an exception to the switch statement.
-		return false
+	return nil
+}
+
+// fieldValue returns the value of the named field of obj formatted as a string; fields that
+// contain multiple values are comma separated.
+func fieldValue(obj interface{}, field string) string {
+	values, ok := h.FieldValues(obj, field)
+	if !ok {
+		return ""
+	}
+
+	return h.FormatValues(values)
+}
+
+// fieldRows returns a row of field values for each object in slice.
+func fieldRows(fields []string, slice []interface{}) [][]string {
+	rows := [][]string{}
+	for _, s := range slice {
+		row := []string{}
+		for _, f := range fields {
+			row = append(row, fieldValue(s, f))
+		}
+		rows = append(rows, row)
 	}
 
-	match, _ := regexp.MatchString(strings.ToLower(filterString), strings.ToLower(value))
+	return rows
+}
 
-	return match
+// customColumn represents a single column of the custom-columns output type.
+type customColumn struct {
+	header string
+	path   string
 }
 
-func filterOutput(result interface{}, filter string) interface{} {
-	if filter == "" {
-		return result
+// parseCustomColumns parses a custom-columns spec in the form of "HEADER:path[,HEADER:path]".
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	columns := []customColumn{}
+	for _, c := range strings.Split(spec, ",") {
+		split := strings.SplitN(c, ":", 2)
+		if len(split) != 2 || split[0] == "" || split[1] == "" {
+			return nil, &invalidInputError{fmt.Sprintf("Invalid custom column \"%s\", columns should be in the form of \"HEADER:field\"", c)}
+		}
+		columns = append(columns, customColumn{header: split[0], path: split[1]})
 	}
 
-	slice := h.InterfaceSlice(result)
+	return columns, nil
+}
 
-	if len(slice) == 0 {
-		return result
+func printCustomColumns(spec, cosmicType string, result interface{}) error {
+	columns, err := parseCustomColumns(spec)
+	if err != nil {
+		return err
+	}
+
+	header := []string{}
+	paths := []string{}
+	for _, c := range columns {
+		header = append(header, c.header)
+		paths = append(paths, c.path)
+	}
+	if err := validateColumns(reflect.TypeOf(result).Elem(), cosmicType, paths); err != nil {
+		return err
 	}
 
-	for i := 0; i < len(slice); i++ {
-		if !filterMatch(slice[i], filter) {
-			slice = append(slice[:i], slice[i+1:]...)
-			i-- // -1 as the slice just got shorter.
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader(header)
+	for _, s := range h.InterfaceSlice(result) {
+		row := []string{}
+		for _, c := range columns {
+			row = append(row, fieldValue(s, c.path))
 		}
+		table.Append(row)
 	}
+	table.Render()
 
-	return slice
+	return nil
 }
 
-func filterSplit(filter string) (field, value string) {
-	if validFilter, _ := regexp.MatchString("=", filter); !validFilter {
-		fmt.Println("Invalid filter string passed, filters should be in the form of \"field=value\".")
-		os.Exit(1)
+func printCSV(fields []string, result interface{}) error {
+	slice := h.InterfaceSlice(result)
+
+	header := []string{}
+	for _, f := range fields {
+		header = append(header, strings.ToLower(f))
 	}
 
-	split := strings.Split(filter, "=")
-	filterField := strings.TrimSpace(split[0])
-	filterString := strings.TrimSpace(split[1])
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(fieldRows(fields, slice)); err != nil {
+		return err
+	}
 
-	return filterField, filterString
+	return nil
 }
 
 func printTable(cosmicType string, fields []string, result interface{}) {
@@ -125,44 +175,10 @@ func printTable(cosmicType string, fields []string, result interface{}) {
 		return
 	}
 
-	sort.Strings(fields)
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAutoWrapText(false)
 	table.SetHeader(fields)
-
-	for _, s := range slice {
-		row := []string{}
-		val := reflect.Indirect(reflect.ValueOf(s))
-		for _, f := range fields {
-			fn := strings.Title(strings.ToLower(f))
-			// We have some exceptions where the field name does not exist on the reflected object.
-			switch fn {
-			// *cosmic.VirtualMachine does not contain a "ipaddress" field so we need to manually
-			// add the primary NIC IP to our table.
-			case "Ipaddress":
-				if cosmicType == "instance" {
-					row = append(row, fmt.Sprintf("%v", val.FieldByName("Nic").Index(0).FieldByName("Ipaddress")))
-					break
-				}
-				fallthrough
-			// "Version" is a lot prettier to print and more user friendly than "LastStartVersion".
-			case "Version":
-				if cosmicType == "instance" {
-					row = append(row, fmt.Sprintf("%v", val.FieldByName("Laststartversion")))
-					break
-				}
-				fallthrough
-			default:
-				v := val.FieldByName(fn)
-				if !v.IsValid() {
-					break
-				}
-				row = append(row, fmt.Sprintf("%v", v))
-			}
-		}
-		table.Append(row)
-	}
-
+	table.AppendBulk(fieldRows(fields, slice))
 	table.Render()
 
 	if len(slice) > 1 {
@@ -171,12 +187,82 @@ func printTable(cosmicType string, fields []string, result interface{}) {
 	fmt.Printf("Found %d %s.\n", len(slice), cosmicType)
 }
 
-func printResult(outputType, cosmicType string, filter, fields []string, result interface{}) {
-	for _, f := range filter {
-		result = filterOutput(result, f)
+func printTemplate(text string, result interface{}) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return &invalidInputError{fmt.Sprintf("Invalid template: %s", err)}
+	}
+
+	for _, s := range h.InterfaceSlice(result) {
+		if err := tmpl.Execute(os.Stdout, s); err != nil {
+			return &invalidInputError{fmt.Sprintf("Invalid template: %s", err)}
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func printYAML(fields []string, result interface{}) error {
+	slice := h.InterfaceSlice(result)
+
+	items := []yaml.MapSlice{}
+	for _, row := range fieldRows(fields, slice) {
+		item := yaml.MapSlice{}
+		for i, f := range fields {
+			item = append(item, yaml.MapItem{Key: strings.ToLower(f), Value: row[i]})
+		}
+		items = append(items, item)
+	}
+
+	b, err := yaml.Marshal(items)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+
+	return nil
+}
+
+func printJSON(result interface{}) error {
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
 	}
+	fmt.Println(string(b))
+
+	return nil
+}
+
+func printResult(outputType, cosmicType string, filter, fields []string, result interface{}) error {
+	filters, err := parseFilters(filter, cosmicType, reflect.TypeOf(result).Elem())
+	if err != nil {
+		return err
+	}
+	result = filterOutput(result, filters)
+
+	// Output types that take an argument are passed as "type=argument".
+	outputArg := ""
+	if split := strings.SplitN(outputType, "=", 2); len(split) == 2 {
+		outputType, outputArg = split[0], split[1]
+	}
+
 	switch {
+	case strings.EqualFold(outputType, "custom-columns"):
+		return printCustomColumns(outputArg, cosmicType, result)
+	case strings.EqualFold(outputType, "go-template"), strings.EqualFold(outputType, "template"):
+		return printTemplate(outputArg, result)
+	case strings.EqualFold(outputType, "csv"):
+		return printCSV(fields, result)
+	case strings.EqualFold(outputType, "json"):
+		return printJSON(result)
 	case strings.EqualFold(outputType, "table"):
 		printTable(cosmicType, fields, result)
+	case strings.EqualFold(outputType, "yaml"):
+		return printYAML(fields, result)
+	default:
+		return &invalidInputError{fmt.Sprintf("Invalid output type \"%s\", provide either \"csv\", \"custom-columns=...\", \"go-template=...\", \"json\", \"table\" or \"yaml\"", outputType)}
 	}
+
+	return nil
 }
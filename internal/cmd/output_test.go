@@ -0,0 +1,116 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"fmt"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+)
+
+func Example_printResultJSON() {
+	acls := cosmic.ACLs{
+		&cosmic.ACL{
+			NetworkACLList: &gocosmic.NetworkACLList{Id: "1234", Name: "acl1"},
+			Vpcname:        "vpc1",
+			Zonename:       "zone1",
+		},
+	}
+	printResult("json", "ACL", nil, nil, acls)
+
+	// Output:
+	// [
+	//   {
+	//     "id": "1234",
+	//     "name": "acl1",
+	//     "vpcname": "vpc1",
+	//     "zonename": "zone1"
+	//   }
+	// ]
+}
+
+func Example_printResultCSV() {
+	vpcs := cosmic.VPCs{
+		&cosmic.VPC{VPC: &gocosmic.VPC{Name: "vpc1", Cidr: "10.0.0.0/16", Zonename: "zone1"}},
+		&cosmic.VPC{VPC: &gocosmic.VPC{Name: "vpc2", Cidr: "10.1.0.0/16", Zonename: "zone2"}},
+	}
+	printResult("csv", "VPC", nil, []string{"Name", "CIDR", "ZoneName"}, vpcs)
+
+	// Output:
+	// name,cidr,zonename
+	// vpc1,10.0.0.0/16,zone1
+	// vpc2,10.1.0.0/16,zone2
+}
+
+func Example_printResultYAML() {
+	vpcs := cosmic.VPCs{
+		&cosmic.VPC{VPC: &gocosmic.VPC{Name: "vpc1", Cidr: "10.0.0.0/16", Zonename: "zone1"}},
+	}
+	printResult("yaml", "VPC", nil, []string{"Name", "CIDR", "ZoneName"}, vpcs)
+
+	// Output:
+	// - name: vpc1
+	//   cidr: 10.0.0.0/16
+	//   zonename: zone1
+}
+
+func Example_printResultTemplate() {
+	acls := cosmic.ACLs{
+		&cosmic.ACL{NetworkACLList: &gocosmic.NetworkACLList{Name: "acl1"}, Zonename: "zone1"},
+		&cosmic.ACL{NetworkACLList: &gocosmic.NetworkACLList{Name: "acl2"}, Zonename: "zone2"},
+	}
+	printResult("go-template={{.Name}} {{.Zonename}}", "ACL", nil, nil, acls)
+
+	// Output:
+	// acl1 zone1
+	// acl2 zone2
+}
+
+func Example_printResultCustomColumns() {
+	acls := cosmic.ACLs{
+		&cosmic.ACL{NetworkACLList: &gocosmic.NetworkACLList{Name: "acl1"}, Zonename: "zone1"},
+	}
+	printResult("custom-columns=NAME:name,ZONE:Zonename", "ACL", nil, nil, acls)
+
+	// Output:
+	// +------+-------+
+	// | NAME | ZONE  |
+	// +------+-------+
+	// | acl1 | zone1 |
+	// +------+-------+
+}
+
+func Example_selectFields() {
+	cfg := &config.Config{ExtraColumns: []string{"memory", "Cpunumber"}}
+	fields, _ := selectFields(cfg, "instance", []string{"Zonename", "Name"}, cosmic.VirtualMachines{})
+	fmt.Println(fields)
+
+	cfg = &config.Config{Columns: []string{"name", "ipaddress"}}
+	fields, _ = selectFields(cfg, "instance", []string{"Name"}, cosmic.VirtualMachines{})
+	fmt.Println(fields)
+
+	cfg = &config.Config{Columns: []string{"Name", "Nonexistent"}}
+	_, err := selectFields(cfg, "instance", []string{"Name"}, cosmic.VirtualMachines{})
+	fmt.Println(err)
+
+	// Output:
+	// [Name Zonename memory Cpunumber]
+	// [name ipaddress]
+	// Invalid column "Nonexistent", no such field exists for instance
+}
@@ -16,7 +16,7 @@
 
 package cmd
 
-func ExampleRunVersionCmd() {
+func Example_runVersionCmd() {
 	runVersionCmd()
 	// Output: cosmic-cli v0.1.0
 }
@@ -17,6 +17,8 @@
 package cmd
 
 import (
+	"context"
+
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
 	"github.com/spf13/cobra"
@@ -38,18 +40,20 @@ func newVPCCmd() *cobra.Command {
 	return cmd
 }
 
-func getVPC(cfg *config.Config) (*cosmic.VPC, error) {
+func getVPC(ctx context.Context, cfg *config.Config) (*cosmic.VPC, error) {
 	var err error
 	vpcs := []*cosmic.VPC{}
 	if cfg.VPCID != "" {
 		vpcs, err = cosmic.VPCGetAllByID(
-			cosmic.NewAsyncClients(cfg),
+			ctx,
+			newClients(cfg),
 			cfg.VPCID,
 		)
 	}
 	if cfg.VPCName != "" {
 		vpcs, err = cosmic.VPCGetAllByName(
-			cosmic.NewAsyncClients(cfg),
+			ctx,
+			newClients(cfg),
 			cfg.VPCName,
 		)
 	}
@@ -59,3 +63,9 @@ func getVPC(cfg *config.Config) (*cosmic.VPC, error) {
 
 	return vpcs[0], nil
 }
+
+// vpcClients returns a client map containing only the client of the profile v was returned by, so
+// changes to v don't fail because an unrelated profile is unavailable.
+func vpcClients(cfg *config.Config, v *cosmic.VPC) map[string]*cosmic.Client {
+	return map[string]*cosmic.Client{v.Profile: newClients(cfg)[v.Profile]}
+}
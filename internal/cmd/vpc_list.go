@@ -17,8 +17,6 @@
 package cmd
 
 import (
-	"os"
-
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
 	"github.com/spf13/cobra"
@@ -31,9 +29,11 @@ func newVPCListCmd() *cobra.Command {
 		Short: "List VPCs",
 		PreRun: func(cmd *cobra.Command, args []string) {
 			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("columns", cmd.Flags().Lookup("columns"))
+			viper.BindPFlag("extra-columns", cmd.Flags().Lookup("extra-columns"))
 			viper.BindPFlag("filter", cmd.Flags().Lookup("filter"))
-			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
 			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
 			viper.BindPFlag("reverse-sort", cmd.Flags().Lookup("reverse-sort"))
 			viper.BindPFlag("show-id", cmd.Flags().Lookup("show-id"))
 			viper.BindPFlag("show-redundant-status", cmd.Flags().Lookup("show-redundant-status"))
@@ -41,11 +41,8 @@ func newVPCListCmd() *cobra.Command {
 			viper.BindPFlag("show-snat", cmd.Flags().Lookup("show-snat"))
 			viper.BindPFlag("sort-by", cmd.Flags().Lookup("sort-by"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runVPCListCmd(); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVPCListCmd()
 		},
 	}
 
@@ -55,12 +52,12 @@ func newVPCListCmd() *cobra.Command {
 	cmd.Flags().BoolP("show-redundant-status", "", false, "show VPC redundant router status in result")
 	cmd.Flags().BoolP("show-restart-required", "", false, "show VPC restart required status in result")
 	cmd.Flags().BoolP("show-snat", "", false, "show VPC Source NAT IP in result")
-	cmd.Flags().StringSliceP("filter", "f", nil, "filter results (supports regex)")
-	cmd.Flags().StringP("output", "o", "table", "specify output type")
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
-	cmd.Flags().StringP("sort-by", "s", "name", "field to sort by")
-
-	cmd.Flags().MarkHidden("output") // Not in use yet.
+	cmd.Flags().StringSliceP("columns", "", nil, "fields to show in result, replacing the default fields")
+	cmd.Flags().StringSliceP("extra-columns", "", nil, "additional fields to show in result")
+	cmd.Flags().StringSliceP("filter", "f", nil, "filter results, e.g. \"name=^web\", \"memory>8192\" or \"ipaddress in 10.1.0.0/16\"")
+	cmd.Flags().StringP("output", "o", "table", "specify output type (csv, custom-columns=..., go-template=..., json, table, yaml)")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
+	cmd.Flags().StringP("sort-by", "s", "name", "field(s) to sort by, e.g. \"zonename,name\"")
 
 	return cmd
 }
@@ -71,15 +68,20 @@ func runVPCListCmd() error {
 		return err
 	}
 
-	vpcs, err := cosmic.ListVPCs(cosmic.NewAsyncClients(cfg))
-	if err != nil {
+	ctx, cancel := newContext()
+	defer cancel()
+
+	// Results of profiles that did not fail are still printed when some profiles fail.
+	failures := &cosmic.ProfileErrors{}
+
+	vpcs, err := cosmic.ListVPCs(ctx, newClients(cfg))
+	if err = failures.Collect(err); err != nil {
 		return err
 	}
-	vpcs.Sort(cfg.SortBy, cfg.ReverseSort)
 
 	if cfg.ShowSNAT {
-		publicIPs, err := cosmic.ListPublicIPAddresses(cosmic.NewAsyncClients(cfg))
-		if err != nil {
+		publicIPs, err := cosmic.ListPublicIPAddresses(ctx, newClients(cfg))
+		if err = failures.Collect(err); err != nil {
 			return err
 		}
 		for _, p := range publicIPs {
@@ -95,6 +97,10 @@ func runVPCListCmd() error {
 		}
 	}
 
+	if err := vpcs.Sort(cfg.SortBy, cfg.ReverseSort); err != nil {
+		return err
+	}
+
 	// Print output
 	fields := []string{"Name", "CIDR", "VPCOfferingName", "ZoneName"}
 	if cfg.ShowID {
@@ -109,7 +115,14 @@ func runVPCListCmd() error {
 	if cfg.ShowRestartRequired {
 		fields = append(fields, "RestartRequired")
 	}
-	printResult(cfg.Output, "VPC", cfg.Filter, fields, vpcs)
+	fields, err = selectFields(cfg, "VPC", fields, vpcs)
+	if err != nil {
+		return err
+	}
+
+	if err := printResult(cfg.Output, "VPC", cfg.Filter, fields, vpcs); err != nil {
+		return err
+	}
 
-	return nil
+	return failures.ErrorOrNil()
 }
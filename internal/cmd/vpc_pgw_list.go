@@ -17,8 +17,6 @@
 package cmd
 
 import (
-	"os"
-
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
 	"github.com/spf13/cobra"
@@ -31,30 +29,29 @@ func newVPCPrivateGatewayListCmd() *cobra.Command {
 		Short: "List VPC PrivateGateways",
 		PreRun: func(cmd *cobra.Command, args []string) {
 			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("columns", cmd.Flags().Lookup("columns"))
+			viper.BindPFlag("extra-columns", cmd.Flags().Lookup("extra-columns"))
 			viper.BindPFlag("filter", cmd.Flags().Lookup("filter"))
-			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
 			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
 			viper.BindPFlag("reverse-sort", cmd.Flags().Lookup("reverse-sort"))
 			viper.BindPFlag("show-id", cmd.Flags().Lookup("show-id"))
 			viper.BindPFlag("sort-by", cmd.Flags().Lookup("sort-by"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runVPCPrivateGatewayListCmd(); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVPCPrivateGatewayListCmd()
 		},
 	}
 
 	// Add local flags.
 	cmd.Flags().BoolP("reverse-sort", "", false, "reverse sort order")
 	cmd.Flags().BoolP("show-id", "", false, "show VPC id in result")
-	cmd.Flags().StringSliceP("filter", "f", nil, "filter results (supports regex)")
-	cmd.Flags().StringP("output", "o", "table", "specify output type")
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
-	cmd.Flags().StringP("sort-by", "s", "ipaddress", "field to sort by")
-
-	cmd.Flags().MarkHidden("output") // Not in use yet.
+	cmd.Flags().StringSliceP("columns", "", nil, "fields to show in result, replacing the default fields")
+	cmd.Flags().StringSliceP("extra-columns", "", nil, "additional fields to show in result")
+	cmd.Flags().StringSliceP("filter", "f", nil, "filter results, e.g. \"name=^web\", \"memory>8192\" or \"ipaddress in 10.1.0.0/16\"")
+	cmd.Flags().StringP("output", "o", "table", "specify output type (csv, custom-columns=..., go-template=..., json, table, yaml)")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
+	cmd.Flags().StringP("sort-by", "s", "ipaddress", "field(s) to sort by, e.g. \"zonename,name\"")
 
 	return cmd
 }
@@ -65,11 +62,19 @@ func runVPCPrivateGatewayListCmd() error {
 		return err
 	}
 
-	pgws, err := cosmic.ListVPCPrivateGateways(cosmic.NewAsyncClients(cfg))
-	if err != nil {
+	ctx, cancel := newContext()
+	defer cancel()
+
+	// Results of profiles that did not fail are still printed when some profiles fail.
+	failures := &cosmic.ProfileErrors{}
+
+	pgws, err := cosmic.ListVPCPrivateGateways(ctx, newClients(cfg))
+	if err = failures.Collect(err); err != nil {
+		return err
+	}
+	if err := pgws.Sort(cfg.SortBy, cfg.ReverseSort); err != nil {
 		return err
 	}
-	pgws.Sort(cfg.SortBy, cfg.ReverseSort)
 
 	// Print output
 	fields := []string{"CIDR", "IPAddress", "NetworkName", "VPCCidr", "VPCName", "ZoneName"}
@@ -78,7 +83,14 @@ func runVPCPrivateGatewayListCmd() error {
 		fields = append(fields, "NetworkID")
 		fields = append(fields, "VPCID")
 	}
-	printResult(cfg.Output, "private gateway", cfg.Filter, fields, pgws)
+	fields, err = selectFields(cfg, "private gateway", fields, pgws)
+	if err != nil {
+		return err
+	}
+
+	if err := printResult(cfg.Output, "private gateway", cfg.Filter, fields, pgws); err != nil {
+		return err
+	}
 
-	return nil
+	return failures.ErrorOrNil()
 }
@@ -17,6 +17,13 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
 	"github.com/spf13/cobra"
 )
 
@@ -34,3 +41,118 @@ func newVPCRouteCmd() *cobra.Command {
 
 	return cmd
 }
+
+// routeError returns an error if any of the route changes failed; the error is a partial error
+// if at least one of the route changes succeeded.
+func routeError(action string, failed, total int) error {
+	switch {
+	case failed == 0:
+		return nil
+	case failed < total:
+		return &partialError{fmt.Sprintf("Failed to %s %d of %d routes", action, failed, total)}
+	}
+
+	return fmt.Errorf("Failed to %s %d routes", action, failed)
+}
+
+// removeRoutes deletes routes using at most cfg.Parallelism goroutines.
+func removeRoutes(ctx context.Context, cfg *config.Config, clientMap map[string]*cosmic.Client, routes []*cosmic.StaticRoute) error {
+	descriptions := []string{}
+	for _, r := range routes {
+		descriptions = append(descriptions, fmt.Sprintf("cidr:%s, nexthop:%s", r.Cidr, r.Nexthop))
+	}
+
+	return changeRoutes(ctx, cfg.Parallelism, "delete", descriptions, func(i int) error {
+		fmt.Printf("Deleting route %s ... \n", descriptions[i])
+		return cosmic.DeleteVPCRoute(ctx, clientMap, routes[i].Id)
+	})
+}
+
+// Status of a route change made by changeRoutes.
+const (
+	routePending = iota // The change was not started.
+	routeDone           // The change was made.
+	routeFailed         // The change failed.
+	routeUnknown        // The change was cancelled while in progress, it may or may not have been made.
+)
+
+// changeRoutes calls fn for each of the routes using at most parallelism goroutines and returns an
+// error if any of the changes failed; errors are printed as they occur. routes contains a
+// description of each route, action is used in messages and is either "create" or "delete". When
+// ctx is cancelled no new changes are started and the status of every change is printed.
+func changeRoutes(ctx context.Context, parallelism int, action string, routes []string, fn func(i int) error) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	// Every worker writes to the index of the change it is making, so status needs no locking.
+	status := make([]int, len(routes))
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < parallelism && w < len(routes); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+
+				err := fn(i)
+				switch {
+				case err == nil:
+					status[i] = routeDone
+				case ctx.Err() != nil:
+					status[i] = routeUnknown
+				default:
+					printErr(err)
+					status[i] = routeFailed
+				}
+			}
+		}()
+	}
+
+Loop:
+	for i := range routes {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break Loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	count := map[int]int{}
+	for _, s := range status {
+		count[s]++
+	}
+
+	if ctx.Err() != nil {
+		printRouteStatus(action, routes, status)
+		return &interruptedError{fmt.Sprintf("Interrupted, %d of %d routes were %sd", count[routeDone], len(routes), action)}
+	}
+
+	return routeError(action, count[routeFailed], len(routes))
+}
+
+// printRouteStatus prints the status of each route change to stderr.
+func printRouteStatus(action string, routes []string, status []int) {
+	labels := map[int]string{
+		routePending: "not " + action + "d",
+		routeDone:    action + "d",
+		routeFailed:  "failed",
+		routeUnknown: "unknown",
+	}
+
+	fmt.Fprintf(os.Stderr, "Interrupted before all routes were %sd:\n", action)
+	for _, s := range []int{routeDone, routeFailed, routeUnknown, routePending} {
+		for i, r := range routes {
+			if status[i] == s {
+				fmt.Fprintf(os.Stderr, "  %-12s %s\n", labels[s]+":", r)
+			}
+		}
+	}
+}
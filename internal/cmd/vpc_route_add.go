@@ -17,12 +17,9 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"net"
-	"os"
 	"strings"
-	"sync"
 
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
@@ -40,16 +37,13 @@ func newVPCRouteAddCmd() *cobra.Command {
 			viper.BindPFlag("vpc-id", cmd.Flags().Lookup("vpc-id"))
 			viper.BindPFlag("vpc-name", cmd.Flags().Lookup("vpc-name"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runVPCRouteAddCmd(args); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVPCRouteAddCmd(args)
 		},
 	}
 
 	// Add local flags.
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
 	cmd.Flags().StringP("vpc-id", "", "", "specify VPC id")
 	cmd.Flags().StringP("vpc-name", "", "", "specify VPC name")
 
@@ -62,6 +56,9 @@ func runVPCRouteAddCmd(args []string) error {
 		return err
 	}
 
+	ctx, cancel := newContext()
+	defer cancel()
+
 	// Validate args.
 	if err := validateVPCRouteAddArgs(args); err != nil {
 		return err
@@ -72,12 +69,16 @@ func runVPCRouteAddCmd(args []string) error {
 		return err
 	}
 
+	// Routes are changed based on the existing ones, so cached responses are never used.
+	cfg.NoCache = true
+
 	// Get a list of existing routes.
-	v, err := getVPC(cfg)
+	v, err := getVPC(ctx, cfg)
 	if err != nil {
 		return err
 	}
-	routes, err := cosmic.ListVPCRoutes(cosmic.NewAsyncClients(cfg), v.Id)
+	clientMap := vpcClients(cfg, v)
+	routes, err := cosmic.ListVPCRoutes(ctx, clientMap, v.Id)
 	if err != nil {
 		return err
 	}
@@ -97,64 +98,50 @@ Loop:
 		newCidrs = append(newCidrs, cidr)
 	}
 
-	wg := sync.WaitGroup{}
-	wg.Add(len(newCidrs))
-
+	descriptions := []string{}
 	for _, cidr := range newCidrs {
-		go func(cidr string) error {
-			defer wg.Done()
-
-			fmt.Printf("Creating route cidr:%s, nexthop:%s ... \n", cidr, nextHop)
-			if err := cosmic.CreateVPCRoute(cosmic.NewAsyncClients(cfg), v.Id, nextHop, cidr); err != nil {
-				return err
-			}
-
-			return nil
-		}(cidr)
+		descriptions = append(descriptions, fmt.Sprintf("cidr:%s, nexthop:%s", cidr, nextHop))
 	}
-	wg.Wait()
 
-	return nil
+	return changeRoutes(ctx, cfg.Parallelism, "create", descriptions, func(i int) error {
+		fmt.Printf("Creating route %s ... \n", descriptions[i])
+		return cosmic.CreateVPCRoute(ctx, clientMap, v.Id, nextHop, newCidrs[i])
+	})
 }
 
 func validateVPCRouteAddArgs(args []string) error {
 	if len(args) == 0 {
-		cmd := newVPCRouteAddCmd()
-		cmd.Help()
-		os.Exit(0)
+		return errHelp
 	}
 
 	if len(args) != 3 {
-		return errors.New("Incorrect number of parameters passed, this command expects \"<network> via <nexthop>\"")
+		return &invalidInputError{"Incorrect number of parameters passed, this command expects \"<network> via <nexthop>\""}
 	}
 
 	if !strings.EqualFold(args[1], "via") {
-		return errors.New("Invalid parameters passed, this command expects \"<network> via <nexthop>\"")
+		return &invalidInputError{"Invalid parameters passed, this command expects \"<network> via <nexthop>\""}
 	}
 
 	for _, c := range strings.Split(args[0], ",") {
 		if _, _, err := net.ParseCIDR(c); err != nil {
-			return fmt.Errorf("%s is not a valid network CIDR", c)
+			return &invalidInputError{fmt.Sprintf("%s is not a valid network CIDR", c)}
 		}
 	}
 
 	if ip := net.ParseIP(args[2]); ip == nil {
-		return fmt.Errorf("%s is not a valid IP address", args[2])
+		return &invalidInputError{fmt.Sprintf("%s is not a valid IP address", args[2])}
 	}
 
 	return nil
 }
 
 func validateVPCRouteAddCmd(cfg *config.Config) error {
-	cmd := newVPCRouteAddCmd()
-
 	if cfg.VPCID != "" && cfg.VPCName != "" {
-		return errors.New("Cannot specify --vpc-id and --vpc-name together")
+		return &invalidInputError{"Cannot specify --vpc-id and --vpc-name together"}
 	}
 
 	if cfg.VPCID == "" && cfg.VPCName == "" {
-		cmd.Help()
-		os.Exit(0)
+		return errHelp
 	}
 
 	return nil
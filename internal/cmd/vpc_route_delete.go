@@ -17,12 +17,8 @@
 package cmd
 
 import (
-	"errors"
-	"fmt"
-	"os"
 	"regexp"
 	"strings"
-	"sync"
 
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
@@ -40,16 +36,13 @@ func newVPCRouteDeleteCmd() *cobra.Command {
 			viper.BindPFlag("vpc-id", cmd.Flags().Lookup("vpc-id"))
 			viper.BindPFlag("vpc-name", cmd.Flags().Lookup("vpc-name"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runVPCRouteDeleteCmd(args); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVPCRouteDeleteCmd(args)
 		},
 	}
 
 	// Add local flags.
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
 	cmd.Flags().StringP("vpc-id", "", "", "specify VPC id")
 	cmd.Flags().StringP("vpc-name", "", "", "specify VPC name")
 
@@ -62,8 +55,12 @@ func runVPCRouteDeleteCmd(args []string) error {
 		return err
 	}
 
+	ctx, cancel := newContext()
+	defer cancel()
+
 	// Validate args.
-	if err := validateVPCRouteDeleteArgs(args); err != nil {
+	key, value, err := validateVPCRouteDeleteArgs(args)
+	if err != nil {
 		return err
 	}
 
@@ -72,26 +69,29 @@ func runVPCRouteDeleteCmd(args []string) error {
 		return err
 	}
 
+	// Routes are changed based on the existing ones, so cached responses are never used.
+	cfg.NoCache = true
+
 	// Get a list of existing routes.
-	v, err := getVPC(cfg)
+	v, err := getVPC(ctx, cfg)
 	if err != nil {
 		return err
 	}
-	routes, err := cosmic.ListVPCRoutes(cosmic.NewAsyncClients(cfg), v.Id)
+	clientMap := vpcClients(cfg, v)
+	routes, err := cosmic.ListVPCRoutes(ctx, clientMap, v.Id)
 	if err != nil {
 		return err
 	}
 
 	// Delete routes from VPC.
-	split := strings.Split(args[0], "=")
 	deleteRoutes := []*cosmic.StaticRoute{}
-	for _, v := range strings.Split(split[1], ",") {
+	for _, v := range strings.Split(value, ",") {
 		for _, r := range routes {
 			match := false
-			if split[0] == "cidr" {
+			if key == "cidr" {
 				match, _ = regexp.MatchString(v, r.Cidr)
 			}
-			if split[0] == "nexthop" {
+			if key == "nexthop" {
 				match, _ = regexp.MatchString(v, r.Nexthop)
 			}
 			// Continue if we don't find a matching cidr or nexthop
@@ -101,55 +101,36 @@ func runVPCRouteDeleteCmd(args []string) error {
 		}
 	}
 
-	wg := sync.WaitGroup{}
-	wg.Add(len(deleteRoutes))
-
-	for _, r := range deleteRoutes {
-		go func(r *cosmic.StaticRoute) error {
-			defer wg.Done()
-
-			fmt.Printf("Deleting route cidr:%s, nexthop:%s ... \n", r.Cidr, r.Nexthop)
-			if err := cosmic.DeleteVPCRoute(cosmic.NewAsyncClients(cfg), r.Id); err != nil {
-				return err
-			}
-
-			return nil
-		}(r)
-	}
-	wg.Wait()
-
-	return nil
+	return removeRoutes(ctx, cfg, clientMap, deleteRoutes)
 }
 
-func validateVPCRouteDeleteArgs(args []string) error {
+// validateVPCRouteDeleteArgs validates args and returns the lower-cased key and the value of the
+// "key=value" argument.
+func validateVPCRouteDeleteArgs(args []string) (key, value string, err error) {
 	if len(args) == 0 {
-		cmd := newVPCRouteDeleteCmd()
-		cmd.Help()
-		os.Exit(0)
+		return "", "", errHelp
 	}
 
 	if len(args) != 1 {
-		return errors.New("Incorrect number of parameters passed")
+		return "", "", &invalidInputError{"Incorrect number of parameters passed"}
 	}
 
-	split := strings.Split(args[0], "=")
-	if !(strings.EqualFold("cidr", split[0]) || strings.EqualFold("nexthop", split[0])) {
-		return errors.New("This command expects either \"cidr=CIDR[,CIDR,CIDR]\" or \"nexthop=NEXTHOP[,NEXTHOP,NEXTHOP]\"")
+	split := strings.SplitN(args[0], "=", 2)
+	key = strings.ToLower(split[0])
+	if len(split) != 2 || split[1] == "" || (key != "cidr" && key != "nexthop") {
+		return "", "", &invalidInputError{"This command expects either \"cidr=CIDR[,CIDR,CIDR]\" or \"nexthop=NEXTHOP[,NEXTHOP,NEXTHOP]\""}
 	}
 
-	return nil
+	return key, split[1], nil
 }
 
 func validateVPCRouteDeleteCmd(cfg *config.Config) error {
-	cmd := newVPCRouteDeleteCmd()
-
 	if cfg.VPCID != "" && cfg.VPCName != "" {
-		return errors.New("Cannot specify --vpc-id and --vpc-name together")
+		return &invalidInputError{"Cannot specify --vpc-id and --vpc-name together"}
 	}
 
 	if cfg.VPCID == "" && cfg.VPCName == "" {
-		cmd.Help()
-		os.Exit(0)
+		return errHelp
 	}
 
 	return nil
@@ -17,11 +17,6 @@
 package cmd
 
 import (
-	"errors"
-	"fmt"
-	"os"
-	"sync"
-
 	"github.com/shoekstra/cosmic-cli/internal/config"
 	"github.com/shoekstra/cosmic-cli/internal/cosmic"
 	"github.com/spf13/cobra"
@@ -38,16 +33,13 @@ func newVPCRouteFlushCmd() *cobra.Command {
 			viper.BindPFlag("vpc-id", cmd.Flags().Lookup("vpc-id"))
 			viper.BindPFlag("vpc-name", cmd.Flags().Lookup("vpc-name"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runVPCRouteFlushCmd(args); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVPCRouteFlushCmd(args)
 		},
 	}
 
 	// Add local flags.
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
 	cmd.Flags().StringP("vpc-id", "", "", "specify VPC id")
 	cmd.Flags().StringP("vpc-name", "", "", "specify VPC name")
 
@@ -60,52 +52,39 @@ func runVPCRouteFlushCmd(args []string) error {
 		return err
 	}
 
+	ctx, cancel := newContext()
+	defer cancel()
+
 	// Validate the config.
 	if err := validateVPCRouteFlushCmd(cfg); err != nil {
 		return err
 	}
 
+	// Routes are changed based on the existing ones, so cached responses are never used.
+	cfg.NoCache = true
+
 	// Get a list of existing routes.
-	v, err := getVPC(cfg)
+	v, err := getVPC(ctx, cfg)
 	if err != nil {
 		return err
 	}
-	routes, err := cosmic.ListVPCRoutes(cosmic.NewAsyncClients(cfg), v.Id)
+	clientMap := vpcClients(cfg, v)
+	routes, err := cosmic.ListVPCRoutes(ctx, clientMap, v.Id)
 	if err != nil {
 		return err
 	}
 
 	// Delete routes from VPC.
-	wg := sync.WaitGroup{}
-	wg.Add(len(routes))
-
-	for _, r := range routes {
-		go func(r *cosmic.StaticRoute) error {
-			defer wg.Done()
-
-			fmt.Printf("Deleting route cidr:%s, nexthop:%s ... \n", r.Cidr, r.Nexthop)
-			if err := cosmic.DeleteVPCRoute(cosmic.NewAsyncClients(cfg), r.Id); err != nil {
-				return err
-			}
-
-			return nil
-		}(r)
-	}
-	wg.Wait()
-
-	return nil
+	return removeRoutes(ctx, cfg, clientMap, routes)
 }
 
 func validateVPCRouteFlushCmd(cfg *config.Config) error {
-	cmd := newVPCRouteFlushCmd()
-
 	if cfg.VPCID != "" && cfg.VPCName != "" {
-		return errors.New("Cannot specify --vpc-id and --vpc-name together")
+		return &invalidInputError{"Cannot specify --vpc-id and --vpc-name together"}
 	}
 
 	if cfg.VPCID == "" && cfg.VPCName == "" {
-		cmd.Help()
-		os.Exit(0)
+		return errHelp
 	}
 
 	return nil
@@ -17,8 +17,6 @@
 package cmd
 
 import (
-	"errors"
-	"os"
 	"sort"
 	"strings"
 
@@ -34,35 +32,34 @@ func newVPCRouteListCmd() *cobra.Command {
 		Short: "List VPC routes",
 		PreRun: func(cmd *cobra.Command, args []string) {
 			// Bind local flags in the PreRun stage to not overwrite bindings in other commands.
+			viper.BindPFlag("columns", cmd.Flags().Lookup("columns"))
+			viper.BindPFlag("extra-columns", cmd.Flags().Lookup("extra-columns"))
 			viper.BindPFlag("filter", cmd.Flags().Lookup("filter"))
-			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
 			viper.BindPFlag("output", cmd.Flags().Lookup("output"))
+			viper.BindPFlag("profile", cmd.Flags().Lookup("profile"))
 			viper.BindPFlag("reverse-sort", cmd.Flags().Lookup("reverse-sort"))
 			viper.BindPFlag("show-id", cmd.Flags().Lookup("show-id"))
 			viper.BindPFlag("sort-by", cmd.Flags().Lookup("sort-by"))
 			viper.BindPFlag("vpc-id", cmd.Flags().Lookup("vpc-id"))
 			viper.BindPFlag("vpc-name", cmd.Flags().Lookup("vpc-name"))
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if err := runVPCRouteListCmd(); err != nil {
-				printErr(err)
-				os.Exit(1)
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVPCRouteListCmd()
 		},
 	}
 
 	// Add local flags.
 	cmd.Flags().BoolP("reverse-sort", "", false, "reverse sort order")
 	cmd.Flags().BoolP("show-id", "", false, "show VPC id in result")
-	cmd.Flags().StringSliceP("filter", "f", nil, "filter results (supports regex)")
-	cmd.Flags().StringP("output", "o", "table", "specify output type")
-	cmd.Flags().StringP("profile", "p", "", "specify profile(s) to use")
-	cmd.Flags().StringP("sort-by", "s", "cidr", "field to sort by")
+	cmd.Flags().StringSliceP("columns", "", nil, "fields to show in result, replacing the default fields")
+	cmd.Flags().StringSliceP("extra-columns", "", nil, "additional fields to show in result")
+	cmd.Flags().StringSliceP("filter", "f", nil, "filter results, e.g. \"name=^web\", \"memory>8192\" or \"ipaddress in 10.1.0.0/16\"")
+	cmd.Flags().StringP("output", "o", "table", "specify output type (csv, custom-columns=..., go-template=..., json, table, yaml)")
+	cmd.Flags().StringP("profile", "p", "", "specify profile(s), group(s) or pattern(s) to use, e.g. \"ams*,!ams3\"")
+	cmd.Flags().StringP("sort-by", "s", "cidr", "field(s) to sort by, e.g. \"zonename,name\"")
 	cmd.Flags().StringP("vpc-id", "", "", "specify VPC id")
 	cmd.Flags().StringP("vpc-name", "", "", "specify VPC name")
 
-	cmd.Flags().MarkHidden("output") // Not in use yet.
-
 	return cmd
 }
 
@@ -72,23 +69,29 @@ func runVPCRouteListCmd() error {
 		return err
 	}
 
+	ctx, cancel := newContext()
+	defer cancel()
+
 	if err := validateVPCRouteListCmd(cfg); err != nil {
 		return err
 	}
 
-	v, err := getVPC(cfg)
+	v, err := getVPC(ctx, cfg)
 	if err != nil {
 		return err
 	}
 
+	// Results of profiles that did not fail are still printed when some profiles fail.
+	failures := &cosmic.ProfileErrors{}
+
 	// Fetch list of routes and add the VPC name if the next hop is a private
 	// gateway attached to a VPC.
-	routes, err := cosmic.ListVPCRoutes(cosmic.NewAsyncClients(cfg), v.Id)
-	if err != nil {
+	routes, err := cosmic.ListVPCRoutes(ctx, newClients(cfg), v.Id)
+	if err = failures.Collect(err); err != nil {
 		return err
 	}
-	pgws, err := cosmic.ListVPCPrivateGateways(cosmic.NewAsyncClients(cfg))
-	if err != nil {
+	pgws, err := cosmic.ListVPCPrivateGateways(ctx, newClients(cfg))
+	if err = failures.Collect(err); err != nil {
 		return err
 	}
 	for _, r := range routes {
@@ -105,28 +108,34 @@ func runVPCRouteListCmd() error {
 			r.Vpcname = ""
 		}
 	}
-	routes.Sort(cfg.SortBy, cfg.ReverseSort)
+	if err := routes.Sort(cfg.SortBy, cfg.ReverseSort); err != nil {
+		return err
+	}
 
 	// Print output
 	fields := []string{"CIDR", "NextHop", "VPCName"}
 	if cfg.ShowID {
 		fields = append(fields, "ID")
 	}
-	printResult(cfg.Output, "static route", cfg.Filter, fields, routes)
+	fields, err = selectFields(cfg, "static route", fields, routes)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	if err := printResult(cfg.Output, "static route", cfg.Filter, fields, routes); err != nil {
+		return err
+	}
+
+	return failures.ErrorOrNil()
 }
 
 func validateVPCRouteListCmd(cfg *config.Config) error {
-	cmd := newVPCRouteListCmd()
-
 	if cfg.VPCID != "" && cfg.VPCName != "" {
-		return errors.New("Cannot specify --vpc-id and --vpc-name together")
+		return &invalidInputError{"Cannot specify --vpc-id and --vpc-name together"}
 	}
 
 	if cfg.VPCID == "" && cfg.VPCName == "" {
-		cmd.Help()
-		os.Exit(0)
+		return errHelp
 	}
 
 	return nil
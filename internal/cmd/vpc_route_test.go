@@ -0,0 +1,121 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic/fake"
+	"github.com/spf13/viper"
+)
+
+func TestRunVPCRouteAddCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmic-cli-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(file, []byte(`
+[profiles.ams]
+api_url = "https://ams.example.com/client/api"
+api_key = "key"
+secret_key = "secret"
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Set("config", file)
+	viper.Set("vpc-name", "web")
+	defer viper.Reset()
+
+	ams := &fake.Backend{
+		VPCs:         []*gocosmic.VPC{{Id: "vpc-1", Name: "web"}},
+		StaticRoutes: []*gocosmic.StaticRoute{{Id: "route-1", Cidr: "10.1.0.0/16", Nexthop: "10.0.0.1", Vpcid: "vpc-1"}},
+	}
+	newClients = func(*config.Config) map[string]*cosmic.Client {
+		return fake.Clients(map[string]*fake.Backend{"ams": ams})
+	}
+	defer func() { newClients = cosmic.NewAsyncClients }()
+
+	// The existing route is skipped, only the new route is created.
+	if err := runVPCRouteAddCmd([]string{"10.1.0.0/16,10.2.0.0/16", "via", "10.0.0.1"}); err != nil {
+		t.Fatalf("runVPCRouteAddCmd() error = %v", err)
+	}
+
+	got := []string{}
+	for _, r := range ams.StaticRoutes {
+		got = append(got, fmt.Sprintf("%s via %s", r.Cidr, r.Nexthop))
+	}
+	want := []string{"10.1.0.0/16 via 10.0.0.1", "10.2.0.0/16 via 10.0.0.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("routes = %v, want %v", got, want)
+	}
+}
+
+func TestChangeRoutesInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	routes := []string{"cidr:10.0.1.0/24", "cidr:10.0.2.0/24", "cidr:10.0.3.0/24"}
+
+	// The first route is created, the second is interrupted and the third is never started.
+	err := changeRoutes(ctx, 1, "create", routes, func(i int) error {
+		if i == 0 {
+			return nil
+		}
+		cancel()
+		return errors.New("Cancelled")
+	})
+
+	ie, ok := err.(*interruptedError)
+	if !ok {
+		t.Fatalf("changeRoutes() error = %v, want an *interruptedError", err)
+	}
+	if want := "Interrupted, 1 of 3 routes were created"; ie.Error() != want {
+		t.Errorf("changeRoutes() error = %s, want %s", ie, want)
+	}
+	if ExitCode(err) != exitInterrupted {
+		t.Errorf("ExitCode() = %d, want %d", ExitCode(err), exitInterrupted)
+	}
+}
+
+func TestChangeRoutesFailed(t *testing.T) {
+	routes := []string{"cidr:10.0.1.0/24", "cidr:10.0.2.0/24"}
+
+	err := changeRoutes(context.Background(), 2, "delete", routes, func(i int) error {
+		if i == 1 {
+			return errors.New("Route not found")
+		}
+		return nil
+	})
+
+	if _, ok := err.(*partialError); !ok || err.Error() != "Failed to delete 1 of 2 routes" {
+		t.Errorf("changeRoutes() error = %v, want a partial error", err)
+	}
+}
@@ -18,8 +18,10 @@ package config
 
 import (
 	"fmt"
-	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
@@ -27,102 +29,320 @@ import (
 
 // Config contains cosmic-cli options.
 type Config struct {
-	ACLID               string   `mapstructure:"acl-id"`
-	ACLName             string   `mapstructure:"acl-name"`
-	Filter              []string `mapstructure:"filter"`
-	InstanceID          string   `mapstructure:"instance-id"`
-	InstanceName        string   `mapstructure:"instance-name"`
-	NetworkID           string   `mapstructure:"network-id"`
-	NetworkName         string   `mapstructure:"network-name"`
-	Output              string   `mapstructure:"output"`
-	Profile             string   `mapstructure:"profile"`
-	ReverseSort         bool     `mapstructure:"reverse-sort"`
-	ShowDescription     bool     `mapstructure:"show-description"`
-	ShowHost            bool     `mapstructure:"show-host"`
-	ShowID              bool     `mapstructure:"show-id"`
-	ShowMACAddress      bool     `mapstructure:"show-mac-address"`
-	ShowACLID           bool     `mapstructure:"show-acl-id"`
-	ShowACLName         bool     `mapstructure:"show-acl-name"`
-	ShowRuleNumber      bool     `mapstructure:"show-rule-number"`
-	ShowNetwork         bool     `mapstructure:"show-network"`
-	ShowRedundantStatus bool     `mapstructure:"show-redundant-status"`
-	ShowRestartRequired bool     `mapstructure:"show-restart-required"`
-	ShowSNAT            bool     `mapstructure:"show-snat"`
-	ShowServiceOffering bool     `mapstructure:"show-service-offering"`
-	ShowTemplate        bool     `mapstructure:"show-template"`
-	ShowVersion         bool     `mapstructure:"show-version"`
-	SortBy              string   `mapstructure:"sort-by"`
-	VPCID               string   `mapstructure:"vpc-id"`
-	VPCName             string   `mapstructure:"vpc-name"`
-	Profiles            map[string]struct {
-		APIURL    string `mapstructure:"api_url"`
-		APIKey    string `mapstructure:"api_key"`
-		SecretKey string `mapstructure:"secret_key"`
-	}
+	ACLID               string              `mapstructure:"acl-id"`
+	ACLName             string              `mapstructure:"acl-name"`
+	CacheTTL            time.Duration       `mapstructure:"cache-ttl"`
+	Columns             []string            `mapstructure:"columns"`
+	Debug               bool                `mapstructure:"debug"`
+	DefaultProfiles     []string            `mapstructure:"default_profiles"`
+	ExtraColumns        []string            `mapstructure:"extra-columns"`
+	Filter              []string            `mapstructure:"filter"`
+	Groups              map[string][]string `mapstructure:"groups"`
+	InstanceID          string              `mapstructure:"instance-id"`
+	InstanceName        string              `mapstructure:"instance-name"`
+	LogFile             string              `mapstructure:"log-file"`
+	NetworkID           string              `mapstructure:"network-id"`
+	NetworkName         string              `mapstructure:"network-name"`
+	NoCache             bool                `mapstructure:"no-cache"`
+	Output              string              `mapstructure:"output"`
+	Parallelism         int                 `mapstructure:"parallelism"`
+	Profile             string              `mapstructure:"profile"`
+	RateLimit           float64             `mapstructure:"rate-limit"`
+	Record              string              `mapstructure:"record"`
+	Refresh             bool                `mapstructure:"refresh"`
+	Replay              string              `mapstructure:"replay"`
+	RetryAttempts       int                 `mapstructure:"retry-attempts"`
+	RetryBackoff        time.Duration       `mapstructure:"retry-backoff"`
+	RetryJitter         float64             `mapstructure:"retry-jitter"`
+	ReverseSort         bool                `mapstructure:"reverse-sort"`
+	ShowDescription     bool                `mapstructure:"show-description"`
+	ShowHost            bool                `mapstructure:"show-host"`
+	ShowID              bool                `mapstructure:"show-id"`
+	ShowMACAddress      bool                `mapstructure:"show-mac-address"`
+	ShowACLID           bool                `mapstructure:"show-acl-id"`
+	ShowACLName         bool                `mapstructure:"show-acl-name"`
+	ShowRuleNumber      bool                `mapstructure:"show-rule-number"`
+	ShowNetwork         bool                `mapstructure:"show-network"`
+	ShowRedundantStatus bool                `mapstructure:"show-redundant-status"`
+	ShowRestartRequired bool                `mapstructure:"show-restart-required"`
+	ShowSNAT            bool                `mapstructure:"show-snat"`
+	ShowServiceOffering bool                `mapstructure:"show-service-offering"`
+	ShowTemplate        bool                `mapstructure:"show-template"`
+	ShowVersion         bool                `mapstructure:"show-version"`
+	SortBy              string              `mapstructure:"sort-by"`
+	Timeout             time.Duration       `mapstructure:"timeout"`
+	Trace               bool                `mapstructure:"trace"`
+	VPCID               string              `mapstructure:"vpc-id"`
+	VPCName             string              `mapstructure:"vpc-name"`
+	Profiles            map[string]Profile
+
+	file     string
+	settings map[string][]string // The settings of each profile as read from the config file.
 }
 
-// ValidProfile will check the profile exists.
-func (c *Config) ValidProfile(p string) {
-	if _, ok := c.Profiles[p]; !ok {
-		log.Fatalf("Cannot find config for specified profile \"%v\"", p)
-	}
+// Profile contains the settings of a single API endpoint. Use Credentials to get the API key and
+// secret key, as they can be stored outside of the config file.
+type Profile struct {
+	APIURL             string        `mapstructure:"api_url"`
+	APIKey             string        `mapstructure:"api_key"`
+	APIKeyFile         string        `mapstructure:"api_key_file"`       // File containing the API key.
+	CAFile             string        `mapstructure:"ca_file"`            // File containing CA certificates to trust.
+	CredentialProcess  string        `mapstructure:"credential_process"` // Command printing the API key and secret key as JSON.
+	DisplayName        string        `mapstructure:"display_name"`       // Descriptive name of the profile.
+	Environment        string        `mapstructure:"environment"`        // Environment label, e.g. "production".
+	InsecureSkipVerify bool          `mapstructure:"insecure_skip_verify"`
+	Proxy              string        `mapstructure:"proxy"`          // HTTP proxy URL, overrides $HTTPS_PROXY and $HTTP_PROXY.
+	RateLimit          float64       `mapstructure:"rate_limit"`     // Overrides the global rate-limit for this profile.
+	Region             string        `mapstructure:"region"`         // Region label, e.g. "eu-west".
+	SecretCommand      string        `mapstructure:"secret_command"` // Command printing the secret key.
+	SecretKey          string        `mapstructure:"secret_key"`
+	SecretKeyFile      string        `mapstructure:"secret_key_file"` // File containing the secret key.
+	Timeout            time.Duration `mapstructure:"timeout"`         // Overrides the global timeout for this profile.
 }
 
-// CheckDuplicatedProfile will check for any profiles; if any find an error will be returned asking
-// the user to fix their config before proceeding.
-func (c *Config) CheckDuplicatedProfile() {
-	duplicate := false
-
-	for p, q := range c.Profiles {
-		if _, ok := c.Profiles[p]; ok {
-			for k, v := range c.Profiles {
-				if _, ok := c.Profiles[k]; ok {
-					if p == k {
-						continue
-					}
-
-					if q == v {
-						fmt.Printf("Duplicate profiles found: \"%s\" is a duplicate of \"%s\" \n", k, p)
-						delete(c.Profiles, k)
-						duplicate = true
-					}
-				}
+// DuplicateProfiles returns an error for each profile that has the same settings as another
+// profile, sorted by profile name.
+func (c *Config) DuplicateProfiles() []error {
+	names := c.profileNames()
+
+	errs := []error{}
+	duplicates := map[string]bool{}
+	for i, p := range names {
+		if duplicates[p] {
+			continue
+		}
+		for _, k := range names[i+1:] {
+			if !duplicates[k] && c.Profiles[p] == c.Profiles[k] {
+				errs = append(errs, &DuplicateProfileError{Profile: p, Duplicate: k})
+				duplicates[k] = true
 			}
 		}
 	}
 
-	if duplicate {
-		log.Fatal("Please remove duplicate profiles before continuing.")
-	}
+	return errs
 }
 
+// Environment variables used to configure cosmic-cli.
+const (
+	EnvConfig      = "COSMIC_CLI_CONFIG"   // Path of the config file to use.
+	EnvAPIURL      = "COSMIC_API_URL"      // API URL of the ad-hoc profile.
+	EnvAPIKey      = "COSMIC_API_KEY"      // API key of the ad-hoc profile.
+	EnvSecretKey   = "COSMIC_SECRET_KEY"   // Secret key of the ad-hoc profile.
+	EnvProfileName = "COSMIC_PROFILE_NAME" // Name of the ad-hoc profile, defaults to "env".
+)
+
 // New returns an initialized Config.
+//
+// The config file is the first of the following that is set or exists:
+//
+//  1. the file passed using --config
+//  2. the file set in $COSMIC_CLI_CONFIG
+//  3. $XDG_CONFIG_HOME/cosmic-cli/config.toml, $XDG_CONFIG_HOME defaults to ~/.config
+//  4. ~/.cosmic-cli/config.toml
+//  5. cosmic-cli/config.toml in any of $XDG_CONFIG_DIRS, which defaults to /etc/xdg
+//
+// When $COSMIC_API_URL, $COSMIC_API_KEY and $COSMIC_SECRET_KEY are set, an ad-hoc profile named
+// after $COSMIC_PROFILE_NAME, or "env" if not set, is added to the profiles in the config file. It
+// replaces a profile with the same name and no config file is needed when it is used.
 func New() (*Config, error) {
-	viper.AddConfigPath(configPath())
-	viper.SetConfigName("config")
-	viper.SetConfigType("toml")
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for any duplicate profiles in config file.
+	if errs := cfg.DuplicateProfiles(); len(errs) > 0 {
+		return nil, errs[0]
+	}
 
-	// Try to read in the config file
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("Error loading config: %s", err)
+	// Check the selected profiles exist.
+	if _, err := cfg.SelectProfiles(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Load returns a Config read from the config file and environment like New, without checking for
+// duplicate profiles or that the selected profiles exist. Use Validate to check it for problems.
+//
+// When a recording is replayed using --replay, the config file and environment are not read and
+// the profiles are the profiles used in the recording.
+func Load() (*Config, error) {
+	return load(viper.GetViper())
+}
+
+// LoadFile returns a Config read from file and the environment like Load, using its own viper
+// instance so the global viper used by cosmic-cli is not changed. The config file is searched for
+// like New does when file is empty.
+func LoadFile(file string) (*Config, error) {
+	v := viper.New()
+	if file != "" {
+		v.Set("config", file)
+	}
+
+	return load(v)
+}
+
+// load returns a Config read using v.
+func load(v *viper.Viper) (*Config, error) {
+	// A replayed recording contains the profiles to use, so the config is not read.
+	if replay := v.GetString("replay"); replay != "" {
+		cfg := &Config{}
+		if err := v.Unmarshal(cfg); err != nil {
+			return nil, &LoadError{err}
+		}
+		profiles, err := replayProfiles(replay)
+		if err != nil {
+			return nil, &LoadError{err}
+		}
+		cfg.Profiles = profiles
+
+		return cfg, nil
+	}
+
+	file, err := configFile(v)
+	if err != nil {
+		return nil, &LoadError{err}
+	}
+
+	if file != "" {
+		v.SetConfigFile(file)
+		v.SetConfigType("toml")
+
+		// Try to read in the config file
+		if err := v.ReadInConfig(); err != nil {
+			return nil, &LoadError{err}
+		}
 	}
 
 	// Unmarshal the resulting config into our Config struct.
 	cfg := &Config{}
-	err := viper.Unmarshal(cfg)
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, &LoadError{err}
+	}
 
-	// Check for any duplicate profiles in config file.
-	cfg.CheckDuplicatedProfile()
+	if !cfg.addEnvProfile() && file == "" {
+		return nil, &LoadError{fmt.Errorf("no config file found in %s and %s, %s and %s are not set",
+			strings.Join(configFiles(), ", "), EnvAPIURL, EnvAPIKey, EnvSecretKey)}
+	}
+
+	cfg.file = file
+	cfg.settings = profileSettings(v)
 
-	return cfg, err
+	return cfg, nil
 }
 
-func configPath() string {
-	configPath, err := homedir.Expand("~/.cosmic-cli")
+// profileSettings returns the names of the settings of each profile read by v.
+func profileSettings(v *viper.Viper) map[string][]string {
+	result := map[string][]string{}
+
+	profiles, _ := v.Get("profiles").(map[string]interface{})
+	for name, p := range profiles {
+		settings, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key := range settings {
+			result[name] = append(result[name], key)
+		}
+	}
+
+	return result
+}
+
+// File returns the config file the Config was read from, or an empty string if no config file was
+// used.
+func (c *Config) File() string {
+	return c.file
+}
+
+// addEnvProfile adds the ad-hoc profile defined by environment variables to c; it returns false
+// if the environment variables are not set.
+func (c *Config) addEnvProfile() bool {
+	p := Profile{
+		APIURL:    os.Getenv(EnvAPIURL),
+		APIKey:    os.Getenv(EnvAPIKey),
+		SecretKey: os.Getenv(EnvSecretKey),
+	}
+	if p.APIURL == "" || p.APIKey == "" || p.SecretKey == "" {
+		return false
+	}
+
+	name := os.Getenv(EnvProfileName)
+	if name == "" {
+		name = "env"
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	c.Profiles[name] = p
+
+	return true
+}
+
+// configFile returns the config file to use, or an empty string if no config file exists. An
+// error is returned if a config file set using --config or $COSMIC_CLI_CONFIG doesn't exist.
+func configFile(v *viper.Viper) (string, error) {
+	for _, f := range []string{v.GetString("config"), os.Getenv(EnvConfig)} {
+		if f == "" {
+			continue
+		}
+
+		f, err := homedir.Expand(f)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(f); err != nil {
+			return "", err
+		}
+		return f, nil
+	}
+
+	for _, f := range configFiles() {
+		if _, err := os.Stat(f); err == nil {
+			return f, nil
+		}
+	}
+
+	return "", nil
+}
+
+// configFiles returns the locations searched for a config file, in order of precedence.
+func configFiles() []string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome, _ = homedir.Expand("~/.config")
+	}
+
+	files := []string{filepath.Join(configHome, "cosmic-cli", "config.toml")}
+	if path, err := configPath(); err == nil {
+		files = append(files, filepath.Join(path, "config.toml"))
+	}
+
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	for _, d := range filepath.SplitList(configDirs) {
+		files = append(files, filepath.Join(d, "cosmic-cli", "config.toml"))
+	}
+
+	return files
+}
+
+// CacheDir returns the directory containing cached API responses.
+func CacheDir() (string, error) {
+	path, err := configPath()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return "", err
 	}
 
-	return configPath
+	return filepath.Join(path, "cache"), nil
+}
+
+// configPath returns the cosmic-cli directory in the home directory of the user.
+func configPath() (string, error) {
+	return homedir.Expand("~/.cosmic-cli")
 }
@@ -0,0 +1,391 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+// setEnv sets the environment variables in env and returns a function restoring their values.
+func setEnv(env map[string]string) func() {
+	// The home directory is cached by homedir, which would hide changes to $HOME.
+	homedir.DisableCache = true
+
+	old := map[string]string{}
+	for k, v := range env {
+		old[k] = os.Getenv(k)
+		os.Setenv(k, v)
+	}
+
+	return func() {
+		for k, v := range old {
+			os.Setenv(k, v)
+		}
+	}
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmic-cli-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	xdgHome := filepath.Join(dir, "xdg", "cosmic-cli", "config.toml")
+	legacy := filepath.Join(dir, "home", ".cosmic-cli", "config.toml")
+	envFile := filepath.Join(dir, "env.toml")
+	flagFile := filepath.Join(dir, "flag.toml")
+
+	defer setEnv(map[string]string{
+		"HOME":            filepath.Join(dir, "home"),
+		"XDG_CONFIG_HOME": filepath.Join(dir, "xdg"),
+		"XDG_CONFIG_DIRS": filepath.Join(dir, "etc"),
+		EnvConfig:         "",
+	})()
+	defer viper.Reset()
+
+	check := func(want string) {
+		t.Helper()
+		if got, err := configFile(viper.GetViper()); err != nil || got != want {
+			t.Errorf("configFile() = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	check("")
+
+	writeConfig(t, legacy, "")
+	check(legacy)
+
+	writeConfig(t, xdgHome, "")
+	check(xdgHome)
+
+	writeConfig(t, envFile, "")
+	os.Setenv(EnvConfig, envFile)
+	check(envFile)
+
+	writeConfig(t, flagFile, "")
+	viper.Set("config", flagFile)
+	check(flagFile)
+
+	viper.Set("config", filepath.Join(dir, "missing.toml"))
+	if _, err := configFile(viper.GetViper()); err == nil {
+		t.Errorf("configFile() didn't return an error for a missing config file")
+	}
+}
+
+func TestEnvProfile(t *testing.T) {
+	defer setEnv(map[string]string{
+		"XDG_CONFIG_HOME": "/nonexistent",
+		"XDG_CONFIG_DIRS": "/nonexistent",
+		"HOME":            "/nonexistent",
+		EnvConfig:         "",
+		EnvAPIURL:         "https://api.cosmic.local/client/api",
+		EnvAPIKey:         "key",
+		EnvSecretKey:      "secret",
+		EnvProfileName:    "ci",
+	})()
+	defer viper.Reset()
+
+	cfg, err := New()
+	if err != nil {
+		t.Fatalf("New() returned an error: %s", err)
+	}
+
+	p, ok := cfg.Profiles["ci"]
+	if !ok || len(cfg.Profiles) != 1 || p.APIURL != "https://api.cosmic.local/client/api" || p.APIKey != "key" {
+		t.Errorf("New() returned profiles %v, want only the ad-hoc profile \"ci\"", cfg.Profiles)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmic-cli-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer setEnv(map[string]string{EnvConfig: "", EnvAPIURL: ""})()
+	defer viper.Reset()
+
+	file := filepath.Join(dir, "config.toml")
+	writeConfig(t, file, "parallelism = 4\n[profiles.ams1]\napi_url = \"https://ams1.test/client/api\"\ntimeot = \"1m\"\n")
+
+	viper.Set("parallelism", 8)
+
+	cfg, err := LoadFile(file)
+	if err != nil {
+		t.Fatalf("LoadFile() returned an error: %s", err)
+	}
+	if cfg.File() != file || cfg.Parallelism != 4 || cfg.Profiles["ams1"].APIURL != "https://ams1.test/client/api" {
+		t.Errorf("LoadFile() returned %+v, want the settings of %s", cfg, file)
+	}
+
+	// The global viper used by cosmic-cli is left alone.
+	if viper.GetString("config") != "" || viper.GetInt("parallelism") != 8 || viper.IsSet("profiles") {
+		t.Errorf("LoadFile() changed the global viper")
+	}
+
+	// Unknown settings are found in the file read by LoadFile, not in the global viper.
+	found := false
+	for _, err := range cfg.Validate() {
+		found = found || err.Error() == `Profile "ams1": unknown setting "timeot"`
+	}
+	if !found {
+		t.Errorf("Validate() did not report the unknown setting of the file read by LoadFile")
+	}
+}
+
+func TestReplayProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmic-cli-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "recording.jsonl")
+	writeConfig(t, file, `{"profile":"ams1","params":"command=listZones&response=json","status":200,"body":"{}"}
+{"profile":"ams2","params":"command=listZones&response=json","status":200,"body":"{}"}
+{"profile":"ams1","params":"command=listVPCs&response=json","status":200,"body":"{}"}
+`)
+
+	// The config file is not read when a recording is replayed.
+	viper.Set("config", filepath.Join(dir, "missing.toml"))
+	viper.Set("replay", file)
+	defer viper.Reset()
+
+	cfg, err := New()
+	if err != nil {
+		t.Fatalf("New() returned an error: %s", err)
+	}
+	if len(cfg.Profiles) != 2 || cfg.Profiles["ams1"].APIURL == "" || cfg.Profiles["ams2"].APIURL == "" {
+		t.Errorf("New() returned profiles %v, want ams1 and ams2", cfg.Profiles)
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmic-cli-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyFile := filepath.Join(dir, "api_key")
+	writeConfig(t, keyFile, "filekey\n")
+
+	tests := []struct {
+		name      string
+		profile   Profile
+		apiKey    string
+		secretKey string
+		wantErr   bool
+	}{
+		{"plain", Profile{APIKey: "key", SecretKey: "secret"}, "key", "secret", false},
+		{"files", Profile{APIKeyFile: keyFile, SecretKeyFile: keyFile}, "filekey", "filekey", false},
+		{"secret command", Profile{APIKey: "key", SecretCommand: "echo '  cmdsecret  '"}, "key", "cmdsecret", false},
+		{"credential process", Profile{CredentialProcess: `echo '{"api_key":"pkey","secret_key":"psecret"}'`}, "pkey", "psecret", false},
+		{"credential process first", Profile{SecretCommand: "exit 1", APIKeyFile: filepath.Join(dir, "missing"), CredentialProcess: `echo '{"api_key":"pkey","secret_key":"psecret"}'`}, "pkey", "psecret", false},
+		{"failing command", Profile{APIKey: "key", SecretCommand: "exit 1"}, "", "", true},
+		{"missing file", Profile{APIKeyFile: filepath.Join(dir, "missing"), SecretKey: "secret"}, "", "", true},
+		{"missing secret", Profile{APIKey: "key"}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		apiKey, secretKey, err := tt.profile.Credentials()
+		if (err != nil) != tt.wantErr || apiKey != tt.apiKey || secretKey != tt.secretKey {
+			t.Errorf("%s: Credentials() = %q, %q, %v, want %q, %q, error %t", tt.name, apiKey, secretKey, err, tt.apiKey, tt.secretKey, tt.wantErr)
+		}
+	}
+}
+
+func TestSelectProfiles(t *testing.T) {
+	cfg := &Config{
+		Profiles: map[string]Profile{"ams1": {}, "ams2": {}, "rtm1": {}, "lab": {}},
+		Groups: map[string][]string{
+			"prod":  {"ams*", "rtm1"},
+			"all2":  {"prod", "lab"},
+			"loop":  {"loop"},
+			"typos": {"amss1"},
+		},
+	}
+
+	tests := []struct {
+		profile  string
+		defaults []string
+		want     string
+		wantErr  bool
+	}{
+		{"", nil, "ams1,ams2,lab,rtm1", false},
+		{"", []string{"ams*"}, "ams1,ams2", false},
+		{"rtm1", []string{"ams*"}, "rtm1", false},
+		{"ams1,rtm1", nil, "ams1,rtm1", false},
+		{"prod", nil, "ams1,ams2,rtm1", false},
+		{"all2,!ams2", nil, "ams1,lab,rtm1", false},
+		{"all,!lab", nil, "ams1,ams2,rtm1", false},
+		{"!lab", nil, "ams1,ams2,rtm1", false},
+		{"!prod", nil, "lab", false},
+		{"a?s1", nil, "ams1", false},
+		{"unknown", nil, "", true},
+		{"zrh*", nil, "", true},
+		{"loop", nil, "", true},
+		{"typos", nil, "", true},
+		{"ams1,", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		cfg.Profile, cfg.DefaultProfiles = tt.profile, tt.defaults
+
+		got, err := cfg.SelectProfiles()
+		if (err != nil) != tt.wantErr || strings.Join(got, ",") != tt.want {
+			t.Errorf("SelectProfiles() with %q = %v, %v, want %s, error %t", tt.profile, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	for _, tt := range []struct {
+		profile  string
+		defaults []string
+		want     string
+	}{
+		{"loop", nil, `Group "loop" contains itself`},
+		{"ams[", nil, `Invalid profile pattern "ams[": syntax error in pattern`},
+		{"", []string{"ams1", ""}, "Invalid default_profiles, empty profile name"},
+	} {
+		cfg.Profile, cfg.DefaultProfiles = tt.profile, tt.defaults
+
+		_, err := cfg.SelectProfiles()
+		if e, ok := err.(*SelectionError); !ok || e.Error() != tt.want {
+			t.Errorf("SelectProfiles() with %q, %q returned error %v, want a *SelectionError %q", tt.profile, tt.defaults, err, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmic-cli-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.toml")
+	writeConfig(t, file, `
+default_profiles = ["ams*", "lab"]
+
+[groups]
+prod = ["ams1", "fra1"]
+
+[profiles.ams1]
+api_url = "https://ams1.cosmic.local/client/api"
+api_key = "key"
+secret_key = "secret"
+api_ulr = "typo"
+
+[profiles.ams2]
+api_url = "https://ams1.cosmic.local/client/api"
+api_key = "key"
+secret_key = "secret"
+
+[profiles.ams3]
+api_url = "ams3.cosmic.local"
+api_key = "key"
+`)
+
+	defer setEnv(map[string]string{EnvConfig: file, EnvAPIURL: ""})()
+	defer viper.Reset()
+
+	// New stops at the first problem, which is not fatal anymore.
+	if _, err := New(); err == nil || !strings.Contains(err.Error(), `"ams2" is a duplicate of "ams1"`) {
+		t.Errorf("New() returned error %v, want a duplicate profile error", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned an error: %s", err)
+	}
+	if cfg.File() != file {
+		t.Errorf("File() = %q, want %q", cfg.File(), file)
+	}
+
+	want := []string{
+		`Profile "ams1": unknown setting "api_ulr"`,
+		`Profile "ams3": api_url "ams3.cosmic.local" is not a valid http(s) URL`,
+		`Profile "ams3": no secret key configured, set secret_key, secret_key_file, secret_command or credential_process`,
+		`Duplicate profiles found: "ams2" is a duplicate of "ams1"`,
+		`Group "prod": Cannot find config for specified profile "fra1"`,
+		`default_profiles: Cannot find config for specified profile "lab"`,
+	}
+
+	errs := cfg.Validate()
+	got := []string{}
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Validate() returned:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestAddProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmic-cli-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "new", "config.toml")
+	defer setEnv(map[string]string{EnvConfig: file, EnvAPIURL: ""})()
+	defer viper.Reset()
+
+	if got, err := InitFile(); err != nil || got != file {
+		t.Fatalf("InitFile() = %q, %v, want %q", got, err, file)
+	}
+
+	profiles := map[string]Profile{
+		"ams1": {APIURL: "https://ams1.cosmic.local/client/api", APIKey: "key", SecretCommand: "pass show ams1"},
+		"ams2": {APIURL: "https://ams2.cosmic.local/client/api", APIKeyFile: "~/ams2.key", SecretKey: "s\"ecret", RateLimit: 2.5, Timeout: time.Minute},
+		"ams3": {APIURL: "https://ams3.cosmic.local/client/api", APIKey: "k\\ey\ttab", SecretKey: "\x01\a\x7fé"},
+	}
+	for _, name := range []string{"ams1", "ams2", "ams3"} {
+		if err := AddProfile(file, name, profiles[name]); err != nil {
+			t.Fatalf("AddProfile(%q) returned an error: %s", name, err)
+		}
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned an error: %s", err)
+	}
+	for name, want := range profiles {
+		if got := cfg.Profiles[name]; got != want {
+			t.Errorf("Profile %q = %+v, want %+v", name, got, want)
+		}
+	}
+}
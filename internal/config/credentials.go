@@ -0,0 +1,153 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// credentials holds the credentials resolved by Profile.Credentials, so that secret commands are
+// only run once per profile.
+var credentials = struct {
+	sync.Mutex
+	m map[Profile][2]string
+}{m: map[Profile][2]string{}}
+
+// Credentials returns the API key and secret key of the profile. They are resolved in the following
+// order, the first one set is used:
+//
+//   - API key: credential_process, api_key_file, api_key
+//   - Secret key: credential_process, secret_command, secret_key_file, secret_key
+//
+// credential_process must print a JSON object containing "api_key" and "secret_key", the output
+// of secret_command and the content of the files are used with surrounding whitespace removed.
+func (p Profile) Credentials() (apiKey, secretKey string, err error) {
+	credentials.Lock()
+	defer credentials.Unlock()
+
+	if c, ok := credentials.m[p]; ok {
+		return c[0], c[1], nil
+	}
+
+	// credential_process takes precedence, so the other sources aren't used when it's set.
+	if p.CredentialProcess != "" {
+		apiKey, secretKey, err = runCredentialProcess(p.CredentialProcess)
+	} else {
+		apiKey, secretKey, err = p.keys()
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	if apiKey == "" || secretKey == "" {
+		return "", "", fmt.Errorf("No API key or secret key configured")
+	}
+
+	credentials.m[p] = [2]string{apiKey, secretKey}
+
+	return apiKey, secretKey, nil
+}
+
+// runCredentialProcess runs command and returns the API key and secret key it prints as JSON.
+func runCredentialProcess(command string) (apiKey, secretKey string, err error) {
+	out, err := runSecretCommand(command)
+	if err != nil {
+		return "", "", fmt.Errorf("Error running credential_process: %s", err)
+	}
+
+	c := struct {
+		APIKey    string `json:"api_key"`
+		SecretKey string `json:"secret_key"`
+	}{}
+	if err := json.Unmarshal(out, &c); err != nil {
+		return "", "", fmt.Errorf("Error parsing output of credential_process: %s", err)
+	}
+
+	return c.APIKey, c.SecretKey, nil
+}
+
+// keys returns the API key and secret key of the profile set using api_key_file, secret_command,
+// secret_key_file or the plain keys.
+func (p Profile) keys() (apiKey, secretKey string, err error) {
+	apiKey, secretKey = p.APIKey, p.SecretKey
+
+	if p.APIKeyFile != "" {
+		if apiKey, err = readSecretFile(p.APIKeyFile); err != nil {
+			return "", "", err
+		}
+	}
+
+	switch {
+	case p.SecretCommand != "":
+		out, err := runSecretCommand(p.SecretCommand)
+		if err != nil {
+			return "", "", fmt.Errorf("Error running secret_command: %s", err)
+		}
+		secretKey = strings.TrimSpace(string(out))
+	case p.SecretKeyFile != "":
+		if secretKey, err = readSecretFile(p.SecretKeyFile); err != nil {
+			return "", "", err
+		}
+	}
+
+	return apiKey, secretKey, nil
+}
+
+// readSecretFile returns the content of path with surrounding whitespace removed.
+func readSecretFile(path string) (string, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Error reading secret file: %s", err)
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// runSecretCommand runs command using the shell and returns its output. The command can prompt the
+// user for input, e.g. to unlock a password manager.
+func runSecretCommand(command string) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	}
+
+	stdout := &bytes.Buffer{}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
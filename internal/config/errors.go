@@ -0,0 +1,67 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package config
+
+import "fmt"
+
+// LoadError is returned when the config can't be loaded.
+type LoadError struct {
+	Err error
+}
+
+// Error returns the load error message.
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("Error loading config: %s", e.Err)
+}
+
+// UnknownProfileError is returned when a profile selection refers to a profile that doesn't
+// exist, or contains a pattern that doesn't match any profile.
+type UnknownProfileError struct {
+	Name    string
+	Pattern bool // Name is a pattern.
+}
+
+// Error returns the unknown profile error message.
+func (e *UnknownProfileError) Error() string {
+	if e.Pattern {
+		return fmt.Sprintf("No profiles match \"%s\"", e.Name)
+	}
+
+	return fmt.Sprintf("Cannot find config for specified profile \"%s\"", e.Name)
+}
+
+// SelectionError is returned when a profile selection can't be expanded, e.g. because a group
+// contains itself or a pattern is invalid.
+type SelectionError struct {
+	Msg string
+}
+
+// Error returns the selection error message.
+func (e *SelectionError) Error() string {
+	return e.Msg
+}
+
+// DuplicateProfileError is returned when a profile has the same settings as another profile.
+type DuplicateProfileError struct {
+	Profile   string
+	Duplicate string
+}
+
+// Error returns the duplicate profile error message.
+func (e *DuplicateProfileError) Error() string {
+	return fmt.Sprintf("Duplicate profiles found: \"%s\" is a duplicate of \"%s\"", e.Duplicate, e.Profile)
+}
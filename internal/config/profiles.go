@@ -0,0 +1,152 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package config
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// SelectProfiles returns the names of the profiles selected using --profile, or the
+// default_profiles setting if --profile is not set. All profiles are selected if neither is set.
+//
+// The selection is a comma separated list of:
+//
+//   - a profile name, e.g. "ams1"
+//   - a group name defined in [groups], e.g. "prod"
+//   - a glob pattern matching profile names, e.g. "ams*"
+//   - "all", selecting all profiles
+//
+// Any of these can be prefixed with "!" to exclude the profiles it selects, e.g. "all,!lab". If
+// the selection only contains exclusions, they are excluded from all profiles.
+func (c *Config) SelectProfiles() ([]string, error) {
+	items := []string{}
+	if c.Profile != "" {
+		items = strings.Split(c.Profile, ",")
+	} else {
+		items = append(items, c.DefaultProfiles...)
+	}
+
+	if len(items) == 0 {
+		items = []string{"all"}
+	}
+
+	include, exclude := map[string]bool{}, map[string]bool{}
+	includes := 0
+
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		target := include
+		if strings.HasPrefix(item, "!") {
+			item = strings.TrimPrefix(item, "!")
+			target = exclude
+		} else {
+			includes++
+		}
+
+		profiles, err := c.expandProfile(item, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range profiles {
+			target[p] = true
+		}
+	}
+
+	// Only exclusions were given, so exclude them from all profiles.
+	if includes == 0 {
+		for p := range c.Profiles {
+			include[p] = true
+		}
+	}
+
+	result := []string{}
+	for p := range include {
+		if !exclude[p] {
+			result = append(result, p)
+		}
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// expandProfile returns the profiles selected by a single item of a profile selection; seen
+// contains the groups being expanded, to detect groups that contain themselves.
+func (c *Config) expandProfile(item string, seen []string) ([]string, error) {
+	if item == "" {
+		switch {
+		case len(seen) > 0:
+			return nil, &SelectionError{fmt.Sprintf("Invalid group \"%s\", empty profile name", seen[len(seen)-1])}
+		case c.Profile != "":
+			return nil, &SelectionError{fmt.Sprintf("Invalid profile selection \"%s\", empty profile name", c.Profile)}
+		default:
+			return nil, &SelectionError{"Invalid default_profiles, empty profile name"}
+		}
+	}
+
+	if _, ok := c.Profiles[item]; ok {
+		return []string{item}, nil
+	}
+
+	if item == "all" {
+		profiles := []string{}
+		for p := range c.Profiles {
+			profiles = append(profiles, p)
+		}
+		return profiles, nil
+	}
+
+	if members, ok := c.Groups[item]; ok {
+		for _, s := range seen {
+			if s == item {
+				return nil, &SelectionError{fmt.Sprintf("Group \"%s\" contains itself", item)}
+			}
+		}
+
+		profiles := []string{}
+		for _, m := range members {
+			p, err := c.expandProfile(m, append(seen, item))
+			if err != nil {
+				return nil, err
+			}
+			profiles = append(profiles, p...)
+		}
+		return profiles, nil
+	}
+
+	if strings.ContainsAny(item, "*?[") {
+		profiles := []string{}
+		for p := range c.Profiles {
+			match, err := path.Match(item, p)
+			if err != nil {
+				return nil, &SelectionError{fmt.Sprintf("Invalid profile pattern \"%s\": %s", item, err)}
+			}
+			if match {
+				profiles = append(profiles, p)
+			}
+		}
+		if len(profiles) == 0 {
+			return nil, &UnknownProfileError{Name: item, Pattern: true}
+		}
+		return profiles, nil
+	}
+
+	return nil, &UnknownProfileError{Name: item}
+}
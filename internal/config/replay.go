@@ -0,0 +1,55 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// replayProfiles returns a profile for each profile used by the API calls recorded in file, see
+// --record and --replay. The profiles only have an API URL, as the recorded responses are
+// returned instead of calling the API.
+func replayProfiles(file string) (map[string]Profile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles := map[string]Profile{}
+	dec := json.NewDecoder(f)
+	for {
+		var call struct {
+			Profile string `json:"profile"`
+		}
+		if err := dec.Decode(&call); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Error reading recording %s: %s", file, err)
+		}
+		profiles[call.Profile] = Profile{APIURL: "replay:" + call.Profile}
+	}
+
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("Recording %s doesn't contain any API calls", file)
+	}
+
+	return profiles, nil
+}
@@ -0,0 +1,69 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// TLSConfig returns the TLS settings of the profile, or nil if the defaults should be used.
+func (p Profile) TLSConfig() (*tls.Config, error) {
+	if p.CAFile == "" && !p.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: p.InsecureSkipVerify}
+
+	if p.CAFile != "" {
+		path, err := homedir.Expand(p.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading ca_file: %s", err)
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("Error reading ca_file: no certificates found in %s", path)
+		}
+	}
+
+	return cfg, nil
+}
+
+// ProxyURL returns the HTTP proxy of the profile, or nil if the proxy set in the environment
+// should be used.
+func (p Profile) ProxyURL() (*url.URL, error) {
+	if p.Proxy == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(p.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid proxy: %s", err)
+	}
+
+	return u, nil
+}
@@ -0,0 +1,129 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Validate checks the config for problems and returns an error for each problem found. Unlike
+// New it doesn't stop at the first problem, so all of them can be reported at once.
+func (c *Config) Validate() []error {
+	errs := []error{}
+
+	for _, name := range c.profileNames() {
+		p := c.Profiles[name]
+
+		for _, key := range c.unknownProfileKeys(name) {
+			errs = append(errs, fmt.Errorf("Profile \"%s\": unknown setting \"%s\"", name, key))
+		}
+
+		if p.APIURL == "" {
+			errs = append(errs, fmt.Errorf("Profile \"%s\": api_url is not set", name))
+		} else if u, err := url.Parse(p.APIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("Profile \"%s\": api_url \"%s\" is not a valid http(s) URL", name, p.APIURL))
+		}
+
+		if p.APIKey == "" && p.APIKeyFile == "" && p.CredentialProcess == "" {
+			errs = append(errs, fmt.Errorf("Profile \"%s\": no API key configured, set api_key, api_key_file or credential_process", name))
+		}
+		if p.SecretKey == "" && p.SecretKeyFile == "" && p.SecretCommand == "" && p.CredentialProcess == "" {
+			errs = append(errs, fmt.Errorf("Profile \"%s\": no secret key configured, set secret_key, secret_key_file, secret_command or credential_process", name))
+		}
+
+		if p.Proxy != "" {
+			if u, err := url.Parse(p.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("Profile \"%s\": proxy \"%s\" is not a valid URL", name, p.Proxy))
+			}
+		}
+		if p.CAFile != "" {
+			if _, err := p.TLSConfig(); err != nil {
+				errs = append(errs, fmt.Errorf("Profile \"%s\": %s", name, err))
+			}
+		}
+
+		if p.RateLimit < 0 {
+			errs = append(errs, fmt.Errorf("Profile \"%s\": rate_limit must not be negative", name))
+		}
+		if p.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("Profile \"%s\": timeout must not be negative", name))
+		}
+	}
+
+	errs = append(errs, c.DuplicateProfiles()...)
+
+	groups := []string{}
+	for g := range c.Groups {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+
+	for _, g := range groups {
+		if _, ok := c.Profiles[g]; ok {
+			errs = append(errs, fmt.Errorf("Group \"%s\" has the same name as a profile", g))
+			continue
+		}
+		if _, err := c.expandProfile(g, nil); err != nil {
+			errs = append(errs, fmt.Errorf("Group \"%s\": %s", g, err))
+		}
+	}
+
+	for _, item := range c.DefaultProfiles {
+		if _, err := c.expandProfile(strings.TrimPrefix(strings.TrimSpace(item), "!"), nil); err != nil {
+			errs = append(errs, fmt.Errorf("default_profiles: %s", err))
+		}
+	}
+
+	return errs
+}
+
+// profileNames returns the names of all profiles, sorted.
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// unknownProfileKeys returns the settings of a profile in the config file that are not known,
+// which are otherwise silently ignored; these are usually typos.
+func (c *Config) unknownProfileKeys(name string) []string {
+	settings := c.settings[name]
+
+	known := map[string]bool{}
+	t := reflect.TypeOf(Profile{})
+	for i := 0; i < t.NumField(); i++ {
+		known[t.Field(i).Tag.Get("mapstructure")] = true
+	}
+
+	keys := []string{}
+	for _, key := range settings {
+		if !known[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,139 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+// DefaultFile returns the config file to create when no config file exists yet.
+func DefaultFile() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(path, "config.toml"), nil
+}
+
+// InitFile returns the config file new profiles are added to: the file set using --config or
+// $COSMIC_CLI_CONFIG, which doesn't have to exist yet, the config file in use, or DefaultFile if no
+// config file exists.
+func InitFile() (string, error) {
+	for _, f := range []string{viper.GetString("config"), os.Getenv(EnvConfig)} {
+		if f != "" {
+			return homedir.Expand(f)
+		}
+	}
+
+	file, err := configFile(viper.GetViper())
+	if err != nil || file != "" {
+		return file, err
+	}
+
+	return DefaultFile()
+}
+
+// AddProfile appends a profile section to a config file, creating the file and its directory if
+// they don't exist. Settings that are not set are left out.
+func AddProfile(file, name string, p Profile) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\n[profiles.%s]\n", tomlQuote(name))
+
+	v := reflect.ValueOf(p)
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("mapstructure")
+		switch f := v.Field(i).Interface().(type) {
+		case string:
+			if f != "" {
+				fmt.Fprintf(&buf, "%s = %s\n", key, tomlQuote(f))
+			}
+		case bool:
+			if f {
+				fmt.Fprintf(&buf, "%s = true\n", key)
+			}
+		case float64:
+			if f != 0 {
+				fmt.Fprintf(&buf, "%s = %s\n", key, strconv.FormatFloat(f, 'f', -1, 64))
+			}
+		case time.Duration:
+			if f != 0 {
+				fmt.Fprintf(&buf, "%s = %s\n", key, tomlQuote(f.String()))
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+
+	// The file can contain secrets, so only the user may read it.
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// tomlQuote returns s as a TOML basic string.
+func tomlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			// Other control characters can only be written as a unicode escape.
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
@@ -17,21 +17,19 @@
 package cosmic
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"sort"
 	"strings"
-	"sync"
 
 	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
-	h "github.com/shoekstra/cosmic-cli/internal/helper"
 )
 
 // ACL embeds *cosmic.NetworkACLList to allow additional fields.
 type ACL struct {
 	*cosmic.NetworkACLList
-	Vpcname  string
-	Zonename string
+	ProfileMeta
+	Vpcname  string `json:"vpcname,omitempty"`
+	Zonename string `json:"zonename,omitempty"`
 }
 
 // ACLs exists to provide helper methods for []*ACL.
@@ -68,217 +66,121 @@ func (a ACLs) FindByName(name string) ([]*ACL, error) {
 	return r, nil
 }
 
-// Sort will sort ACLs by either the "name", "vpcname" or "zonename" field.
-func (a ACLs) Sort(sortBy string, reverseSort bool) {
-	if !h.Contains([]string{"name", "vpcname", "zonename"}, sortBy) {
-		fmt.Println("Invalid sort option provided, provide either \"name\", \"vpcname\" or \"zonename\".")
-		os.Exit(1)
-	}
-
-	switch {
-	case strings.EqualFold(sortBy, "Name"):
-		sort.SliceStable(a, func(i, j int) bool {
-			if reverseSort {
-				return a[i].Name > a[j].Name
-			}
-			return a[i].Name < a[j].Name
-		})
-	case strings.EqualFold(sortBy, "Vpcname"):
-		sort.SliceStable(a, func(i, j int) bool {
-			if reverseSort {
-				return a[i].Vpcname > a[j].Vpcname
-			}
-			return a[i].Vpcname < a[j].Vpcname
-		})
-	case strings.EqualFold(sortBy, "Zonename"):
-		sort.SliceStable(a, func(i, j int) bool {
-			if reverseSort {
-				return a[i].Zonename > a[j].Zonename
-			}
-			return a[i].Zonename < a[j].Zonename
-		})
-	}
+// Sort will sort ACLs by one or more comma separated fields, e.g. "zonename,name".
+func (a ACLs) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(a, sortBy, reverseSort)
 }
 
-// ListACLs returns a ACLs object using all configured *cosmic.CosmicClient objects.
-func ListACLs(clientMap map[string]*cosmic.CosmicClient) (ACLs, error) {
-	acls := []*ACL{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			// Zonename isn't returned in *cosmic.ListNetworkACLListsResponse so we need to fetch it
-			zoneparams := clientMap[client].Zone.NewListZonesParams()
-			zoneresp, err := clientMap[client].Zone.ListZones(zoneparams)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-			zonename := zoneresp.Zones[0].Name
+// ListACLs returns a ACLs object using all configured *Client objects.
+func ListACLs(ctx context.Context, clientMap map[string]*Client) (ACLs, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		// Zonename isn't returned in *cosmic.ListNetworkACLListsResponse so we need to fetch it
+		zoneparams := client.Zone.NewListZonesParams()
+		zoneresp, err := client.Zone.ListZones(zoneparams)
+		if err != nil {
+			return nil, err
+		}
+		zonename := zoneresp.Zones[0].Name
 
-			// Fetch VPCs so we can translate VPC IDs to names
-			vpcparams := clientMap[client].VPC.NewListVPCsParams()
-			vpcresp, err := clientMap[client].VPC.ListVPCs(vpcparams)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
+		// Fetch VPCs so we can translate VPC IDs to names
+		vpcparams := client.VPC.NewListVPCsParams()
+		vpcresp, err := client.VPC.ListVPCs(vpcparams)
+		if err != nil {
+			return nil, err
+		}
 
-			// Fetch ACLs
-			params := clientMap[client].NetworkACL.NewListNetworkACLListsParams()
-			resp, err := clientMap[client].NetworkACL.ListNetworkACLLists(params)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
+		// Fetch ACLs
+		params := client.NetworkACL.NewListNetworkACLListsParams()
+		resp, err := client.NetworkACL.ListNetworkACLLists(params)
+		if err != nil {
+			return nil, err
+		}
 
-			for _, acl := range resp.NetworkACLLists {
-				vpcname := ""
-				for _, v := range vpcresp.VPCs {
-					if v.Id == acl.Vpcid {
-						vpcname = v.Name
-						break
-					}
+		acls := []*ACL{}
+		for _, acl := range resp.NetworkACLLists {
+			vpcname := ""
+			for _, v := range vpcresp.VPCs {
+				if v.Id == acl.Vpcid {
+					vpcname = v.Name
+					break
 				}
-				acls = append(acls, &ACL{
-					NetworkACLList: acl,
-					Vpcname:        vpcname,
-					Zonename:       zonename,
-				})
 			}
-		}(client)
-	}
+			acls = append(acls, &ACL{
+				NetworkACLList: acl,
+				ProfileMeta:    client.meta,
+				Vpcname:        vpcname,
+				Zonename:       zonename,
+			})
+		}
 
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
+		return acls, nil
+	})
 
-	select {
-	case <-finished:
-	case err := <-errChannel:
-		if err != nil {
-			return nil, err
-		}
+	acls := []*ACL{}
+	for _, r := range results {
+		acls = append(acls, r.([]*ACL)...)
 	}
 
-	return acls, nil
+	return acls, err
 }
 
 // ACLRule embeds *cosmic.NetworkACLRule to allow additional fields.
 type ACLRule struct {
 	*cosmic.NetworkACL
-	Aclname  string
-	Vpcname  string
-	Zonename string
+	ProfileMeta
+	Aclname  string `json:"aclname,omitempty"`
+	Vpcname  string `json:"vpcname,omitempty"`
+	Zonename string `json:"zonename,omitempty"`
 }
 
 // ACLRules exists to provide helper methods for []*ACLRule.
 type ACLRules []*ACLRule
 
-// Sort will sort ACLs by either the "name", "vpcname" or "zonename" field.
-func (rules ACLRules) Sort(sortBy string, reverseSort bool) {
-	if !h.Contains([]string{"action", "cidrlist", "endport", "number", "startport"}, sortBy) {
-		fmt.Println("Invalid sort option provided, provide either \"action\", \"cidrlist\", \"endport\", \"number\" or \"startport\".")
-		os.Exit(1)
-	}
+// Sort will sort ACLRules by one or more comma separated fields, e.g. "zonename,name".
+func (rules ACLRules) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(rules, sortBy, reverseSort)
+}
 
-	switch {
-	case strings.EqualFold(sortBy, "Action"):
-		sort.SliceStable(rules, func(i, j int) bool {
-			if reverseSort {
-				return rules[i].Action > rules[j].Action
-			}
-			return rules[i].Action < rules[j].Action
-		})
-	case strings.EqualFold(sortBy, "Cidrlist"):
-		sort.SliceStable(rules, func(i, j int) bool {
-			if reverseSort {
-				return rules[i].Cidrlist > rules[j].Cidrlist
-			}
-			return rules[i].Cidrlist < rules[j].Cidrlist
-		})
-	case strings.EqualFold(sortBy, "Endport"):
-		sort.SliceStable(rules, func(i, j int) bool {
-			if reverseSort {
-				return rules[i].Endport > rules[j].Endport
+// ListACLRules returns a ACLRules object using all configured *Client objects.
+func ListACLRules(ctx context.Context, clientMap map[string]*Client, aclid string) (ACLRules, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.NetworkACL.NewListNetworkACLsParams()
+		params.SetAclid(aclid)
+		resp, err := client.NetworkACL.ListNetworkACLs(params)
+		if err != nil {
+			if strings.Contains(err.Error(), fmt.Sprintf("does not have permission")) {
+				return nil, nil
 			}
-			return rules[i].Endport < rules[j].Endport
-		})
-	case strings.EqualFold(sortBy, "Number"):
-		sort.SliceStable(rules, func(i, j int) bool {
-			if reverseSort {
-				return rules[i].Number > rules[j].Number
+			if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
+				return nil, nil
 			}
-			return rules[i].Number < rules[j].Number
-		})
-	case strings.EqualFold(sortBy, "Startport"):
-		sort.SliceStable(rules, func(i, j int) bool {
-			if reverseSort {
-				return rules[i].Startport > rules[j].Startport
+			if strings.Contains(err.Error(), fmt.Sprintf("Unable to find VPC associated with acl")) {
+				return nil, nil
 			}
-			return rules[i].Startport < rules[j].Startport
-		})
-	}
-}
-
-// ListACLRules returns a ACLRules object using all configured *cosmic.CosmicClient objects.
-func ListACLRules(clientMap map[string]*cosmic.CosmicClient, aclid string) (ACLRules, error) {
-	acls := []*ACLRule{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
+			return nil, err
+		}
 
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
+		aclname := ""
+		if a, _, err := client.NetworkACL.GetNetworkACLListByID(aclid); err == nil {
+			aclname = a.Name
+		}
 
-			params := clientMap[client].NetworkACL.NewListNetworkACLsParams()
-			params.SetAclid(aclid)
-			resp, err := clientMap[client].NetworkACL.ListNetworkACLs(params)
-			if err != nil {
-				if strings.Contains(err.Error(), fmt.Sprintf("does not have permission")) {
-					return
-				}
-				if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
-					return
-				}
-				if strings.Contains(err.Error(), fmt.Sprintf("Unable to find VPC associated with acl")) {
-					return
-				}
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-			a, _, _ := clientMap[client].NetworkACL.GetNetworkACLListByID(aclid)
-			for _, acl := range resp.NetworkACLs {
-				acls = append(acls, &ACLRule{
-					NetworkACL: acl,
-					Aclname:    a.Name,
-				})
-			}
-		}(client)
-	}
+		rules := []*ACLRule{}
+		for _, acl := range resp.NetworkACLs {
+			rules = append(rules, &ACLRule{
+				NetworkACL:  acl,
+				ProfileMeta: client.meta,
+				Aclname:     aclname,
+			})
+		}
 
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
+		return rules, nil
+	})
 
-	select {
-	case <-finished:
-	case err := <-errChannel:
-		if err != nil {
-			return nil, err
-		}
+	rules := []*ACLRule{}
+	for _, r := range results {
+		rules = append(rules, r.([]*ACLRule)...)
 	}
 
-	return acls, nil
+	return rules, err
 }
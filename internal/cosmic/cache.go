@@ -0,0 +1,178 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cacheHeader is set on responses returned from the cache.
+const cacheHeader = "X-Cosmic-Cli-Cache"
+
+// CacheOptions configures the on-disk cache of API responses.
+type CacheOptions struct {
+	Dir  string        // Directory containing the cache, a subdirectory is used per profile.
+	TTL  time.Duration // Time a cached response is used for, 0 disables caching.
+	Read bool          // Use cached responses; when false responses are only written to the cache.
+}
+
+// ClearCache removes the cached responses of profiles from the cache in dir, or all cached
+// responses when no profiles are given.
+func ClearCache(dir string, profiles ...string) error {
+	if len(profiles) == 0 {
+		return os.RemoveAll(dir)
+	}
+
+	for _, p := range profiles {
+		if err := os.RemoveAll(profileCacheDir(dir, p)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// profileCacheDir returns the directory containing the cached responses of profile.
+func profileCacheDir(dir, profile string) string {
+	return filepath.Join(dir, url.PathEscape(profile))
+}
+
+// cacheTransport is a http.RoundTripper that caches the responses of API commands that don't
+// change anything. Any other command clears the cache of the profile, as it may have changed
+// what the cached responses returned; this is done even when caching is disabled, so a later
+// call that does use the cache never returns stale responses.
+type cacheTransport struct {
+	next http.RoundTripper
+	dir  string // The cache directory of the profile.
+	ttl  time.Duration
+	read bool
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	command := req.URL.Query().Get("command")
+
+	// Async job results are polled until the job finishes, so they are never cached. A finished
+	// job may have changed something, so the cache is cleared as well.
+	if !isSafeCommand(command) || command == "queryAsyncJobResult" {
+		resp, err := t.next.RoundTrip(req)
+		os.RemoveAll(t.dir)
+		return resp, err
+	}
+
+	if t.ttl <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	path := filepath.Join(t.dir, cacheKey(req.URL)+".json")
+
+	if t.read {
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) < t.ttl {
+			if b, err := ioutil.ReadFile(path); err == nil {
+				return cachedResponse(req, b), nil
+			}
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	// Failing to write to the cache doesn't fail the API call.
+	writeCacheFile(path, b)
+
+	return resp, nil
+}
+
+// cacheKey returns the key of an API call; credentials and the signature are left out as they
+// don't change the response.
+func cacheKey(u *url.URL) string {
+	q := u.Query()
+	q.Del("apiKey")
+	q.Del("signature")
+
+	keys := []string{}
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	h.Write([]byte(u.Host + u.Path))
+	for _, k := range keys {
+		h.Write([]byte("\n" + strings.ToLower(k) + "=" + strings.Join(q[k], ",")))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedResponse returns a response for req with body b.
+func cachedResponse(req *http.Request, b []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}, cacheHeader: {"hit"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+		Request:       req,
+	}
+}
+
+// writeCacheFile writes b to path using a temporary file, so concurrent readers never see a
+// partially written file.
+func writeCacheFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
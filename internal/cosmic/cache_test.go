@@ -0,0 +1,127 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	var requests int32
+	ts := newFlakyServer(0, &requests)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "cosmic-cli-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	list := func(read bool) {
+		opts := clientOptions{cache: &CacheOptions{Dir: dir, TTL: time.Minute, Read: read}}
+		clientMap := map[string]*Client{"nl1": newClient("nl1", ts.URL, "key", "secret", opts)}
+		if _, err := ListVMs(context.Background(), clientMap); err != nil {
+			t.Fatalf("ListVMs() returned an error: %s", err)
+		}
+	}
+
+	list(true)
+	list(true)
+	if requests != 1 {
+		t.Errorf("cached ListVMs() sent %d requests, want 1", requests)
+	}
+
+	list(false)
+	if requests != 2 {
+		t.Errorf("refreshed ListVMs() sent %d requests, want 2", requests)
+	}
+
+	// Any call that may change something clears the cache of the profile.
+	tr := &cacheTransport{next: http.DefaultTransport, dir: profileCacheDir(dir, "nl1"), ttl: time.Minute}
+	req, _ := http.NewRequest("GET", ts.URL+"?command=createStaticRoute", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() returned an error: %s", err)
+	}
+	resp.Body.Close()
+
+	if _, err := os.Stat(profileCacheDir(dir, "nl1")); !os.IsNotExist(err) {
+		t.Errorf("createStaticRoute did not clear the cache")
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	var requests int32
+	ts := newFlakyServer(0, &requests)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "cosmic-cli-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A stale response of an earlier run with caching enabled.
+	stale := filepath.Join(profileCacheDir(dir, "nl1"), "stale.json")
+	if err := writeCacheFile(stale, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := &cacheTransport{next: http.DefaultTransport, dir: profileCacheDir(dir, "nl1"), read: true}
+	for _, command := range []string{"listVirtualMachines", "listVirtualMachines"} {
+		req, _ := http.NewRequest("GET", ts.URL+"?command="+command, nil)
+		resp, err := tr.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip() returned an error: %s", err)
+		}
+		resp.Body.Close()
+	}
+	if requests != 2 {
+		t.Errorf("uncached listVirtualMachines sent %d requests, want 2", requests)
+	}
+
+	// Changes clear the cache, even when caching is disabled.
+	req, _ := http.NewRequest("GET", ts.URL+"?command=deleteStaticRoute", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() returned an error: %s", err)
+	}
+	resp.Body.Close()
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("deleteStaticRoute did not clear the cache")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	req1, _ := http.NewRequest("GET", "https://api.test/client/api?command=listVPCs&apiKey=a&signature=x", nil)
+	req2, _ := http.NewRequest("GET", "https://api.test/client/api?apiKey=b&command=listVPCs&signature=y", nil)
+	req3, _ := http.NewRequest("GET", "https://api.test/client/api?command=listVPCs&id=1", nil)
+
+	if cacheKey(req1.URL) != cacheKey(req2.URL) {
+		t.Errorf("cacheKey() depends on the credentials used")
+	}
+	if cacheKey(req1.URL) == cacheKey(req3.URL) {
+		t.Errorf("cacheKey() doesn't depend on the parameters used")
+	}
+}
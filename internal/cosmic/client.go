@@ -0,0 +1,245 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"time"
+	"unsafe"
+
+	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
+)
+
+// ProfileMeta contains the profile a resource was returned by and the labels of that profile. It is
+// embedded in all resources, so its fields can be used as columns, filters and sort keys. The display
+// name is prefixed with "Profile" as several resources have a display name of their own.
+type ProfileMeta struct {
+	Profile            string `json:"profile,omitempty"`
+	ProfileDisplayName string `json:"profile_display_name,omitempty"`
+	Environment        string `json:"environment,omitempty"`
+	Region             string `json:"region,omitempty"`
+}
+
+// Client embeds the API services used by cosmic-cli and holds the profile it was created for.
+type Client struct {
+	Services
+	Profile string
+	Timeout time.Duration // Maximum time API calls using this profile may take, 0 for no limit.
+
+	apiURL    string
+	apiKey    string
+	secretKey string
+	meta      ProfileMeta
+	transport http.RoundTripper
+
+	// services returns the services to use for API calls bound to a context, see withContext.
+	services func(ctx context.Context) Services
+
+	// pool limits the number of profiles used concurrently, it is shared by all clients returned
+	// by a single NewAsyncClients call. A nil pool does not limit anything.
+	pool chan struct{}
+
+	// err is returned for every use of a client that couldn't be created.
+	err error
+}
+
+// clientOptions contains the settings used by newClient.
+type clientOptions struct {
+	cache     *CacheOptions // Cache used for API responses, nil to disable caching.
+	debug     *DebugOptions // Logging of API calls, nil to disable logging.
+	meta      ProfileMeta   // Labels added to the resources returned, the profile is always set.
+	proxy     *url.URL      // HTTP proxy to use, nil to use the proxy set in the environment.
+	rateLimit float64       // Maximum requests per second to the API endpoint, 0 for no limit.
+	record    *recorder     // Recording the API calls are written to, nil to disable recording.
+	registry  *Registry     // Registry of the rate limiters, nil to use the default registry.
+	replay    *replayer     // Recording the responses are returned from instead of calling the API.
+	retry     RetryPolicy   // Policy used to retry failed API calls.
+	timeout   time.Duration // Maximum time API calls may take, 0 for no limit.
+	tlsConfig *tls.Config   // TLS settings, nil to use the defaults.
+
+	// pool is shared by all clients that should be limited together, see Client.
+	pool chan struct{}
+}
+
+// newClient returns a *Client for profile using the API endpoint at apiURL.
+func newClient(profile, apiURL, apiKey, secretKey string, opts clientOptions) *Client {
+	base := &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: opts.tlsConfig}
+	if opts.proxy != nil {
+		base.Proxy = http.ProxyURL(opts.proxy)
+	}
+	var transport http.RoundTripper = base
+
+	if opts.rateLimit > 0 {
+		host := apiURL
+		if u, err := url.Parse(apiURL); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		registry := opts.registry
+		if registry == nil {
+			registry = defaultRegistry
+		}
+		transport = &rateLimitTransport{next: transport, limiter: registry.endpointLimiter(host, opts.rateLimit)}
+	}
+
+	if opts.retry.Attempts > 1 {
+		transport = &retryTransport{next: transport, policy: opts.retry}
+	}
+
+	if opts.cache != nil {
+		transport = &cacheTransport{
+			next: transport,
+			dir:  profileCacheDir(opts.cache.Dir, profile),
+			ttl:  opts.cache.TTL,
+			read: opts.cache.Read,
+		}
+	}
+
+	if opts.record != nil {
+		transport = &recordTransport{next: transport, profile: profile, recorder: opts.record}
+	}
+
+	// A replayed profile doesn't call the API, so caching, retries and rate limiting aren't used.
+	if opts.replay != nil {
+		transport = &replayTransport{profile: profile, replayer: opts.replay}
+	}
+
+	if opts.debug != nil {
+		transport = &debugTransport{next: transport, profile: profile, opts: opts.debug}
+	}
+
+	opts.meta.Profile = profile
+
+	c := &Client{
+		Profile:   profile,
+		Timeout:   opts.timeout,
+		apiURL:    apiURL,
+		apiKey:    apiKey,
+		secretKey: secretKey,
+		meta:      opts.meta,
+		transport: transport,
+		pool:      opts.pool,
+	}
+
+	cs, err := c.newCosmicClient(transport)
+	if err != nil {
+		return &Client{Profile: profile, err: err}
+	}
+	c.Services = newServices(cs)
+
+	// Whether the transport can be set only depends on go-cosmic, so it can't fail once it
+	// succeeded above.
+	c.services = func(ctx context.Context) Services {
+		cs, _ := c.newCosmicClient(&contextTransport{ctx: ctx, next: c.transport})
+		return newServices(cs)
+	}
+
+	return c
+}
+
+// NewClient returns a client for profile that uses services for its API calls instead of an API
+// endpoint, e.g. the in-memory API in internal/cosmic/fake.
+func NewClient(profile string, services Services) *Client {
+	return &Client{
+		Services: services,
+		Profile:  profile,
+		meta:     ProfileMeta{Profile: profile},
+		services: func(context.Context) Services { return services },
+	}
+}
+
+// newCosmicClient returns a *cosmic.CosmicClient that sends its requests using transport.
+func (c *Client) newCosmicClient(transport http.RoundTripper) (*cosmic.CosmicClient, error) {
+	// The HTTP timeout is left to the context passed to withContext.
+	cs := cosmic.NewAsyncClient(c.apiURL, c.apiKey, c.secretKey, nil, 0)
+	if c.Timeout > 0 {
+		cs.AsyncTimeout(asyncTimeout(c.Timeout))
+	}
+	if err := setTransport(cs, transport); err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// asyncTimeout returns timeout in whole seconds as used by go-cosmic to wait for async jobs. It
+// is rounded up, as a timeout of 0 seconds would fail every async job immediately.
+func asyncTimeout(timeout time.Duration) int64 {
+	return int64((timeout + time.Second - 1) / time.Second)
+}
+
+// withContext returns a copy of c of which all API calls are bound to ctx.
+func (c *Client) withContext(ctx context.Context) *Client {
+	cc := *c
+	cc.Services = c.services(ctx)
+
+	return &cc
+}
+
+// acquire blocks until the client is allowed to make API calls or ctx is done.
+func (c *Client) acquire(ctx context.Context) error {
+	if c.pool == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case c.pool <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot taken by acquire.
+func (c *Client) release() {
+	if c.pool != nil {
+		<-c.pool
+	}
+}
+
+// setTransport replaces the transport of the HTTP client used by cs. go-cosmic doesn't expose its
+// HTTP client, so it is looked up using reflection. An error is returned instead of touching
+// the field when go-cosmic no longer stores its client the way this expects.
+func setTransport(cs *cosmic.CosmicClient, transport http.RoundTripper) error {
+	f := reflect.ValueOf(cs).Elem().FieldByName("client")
+	if !f.IsValid() || !f.CanAddr() || f.Type() != reflect.TypeOf(&http.Client{}) || f.IsNil() {
+		return errUnsupportedClient
+	}
+
+	hc := *(**http.Client)(unsafe.Pointer(f.UnsafeAddr()))
+	hc.Transport = transport
+
+	return nil
+}
+
+// errUnsupportedClient is returned when the HTTP client of go-cosmic can't be replaced.
+var errUnsupportedClient = errors.New("unsupported go-cosmic version: unable to set the transport of its HTTP client")
+
+// contextTransport is a http.RoundTripper that binds each request to a context.
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req.WithContext(t.ctx))
+}
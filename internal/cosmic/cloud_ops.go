@@ -17,8 +17,7 @@
 package cosmic
 
 import (
-	"fmt"
-	"sync"
+	"context"
 
 	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
 )
@@ -26,220 +25,194 @@ import (
 // WhoHasThisIP embeds *cosmic.WhoHasThisIP to allow additional fields.
 type WhoHasThisIP struct {
 	*cosmic.WhoHasThisIp
-	Vpcname  string
-	Zonename string
+	ProfileMeta
+	Vpcname  string `json:"vpcname,omitempty"`
+	Zonename string `json:"zonename,omitempty"`
 }
 
 // WhoHasThisIPs exists to provide helper methods for []*WhoHasThisIP.
 type WhoHasThisIPs []*WhoHasThisIP
 
+// Sort will sort WhoHasThisIPs by one or more comma separated fields, e.g. "zonename,name".
+func (ips WhoHasThisIPs) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(ips, sortBy, reverseSort)
+}
+
 // WhoHasThisMac embeds *cosmic.WhoHasThisMac to allow additional fields.
 type WhoHasThisMac struct {
 	*cosmic.WhoHasThisMac
-	Vpcname  string
-	Zonename string
+	ProfileMeta
+	Vpcname  string `json:"vpcname,omitempty"`
+	Zonename string `json:"zonename,omitempty"`
 }
 
 // WhoHasThisMacs exists to provide helper methods for []*WhoHasThisMac.
 type WhoHasThisMacs []*WhoHasThisMac
 
-// ListIP returns a WhoHasThisIPs object using all configured *cosmic.CosmicClient objects.
-func ListIP(clientMap map[string]*cosmic.CosmicClient, ipaddress string) (WhoHasThisIPs, error) {
-	ips := []*WhoHasThisIP{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			// Zonename isn't returned in *cosmic.ListWhoHasThisIpResponse so we need to fetch it
-			zoneparams := clientMap[client].Zone.NewListZonesParams()
-			zoneresp, err := clientMap[client].Zone.ListZones(zoneparams)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-			zonename := zoneresp.Zones[0].Name
-
-			// VPCName isn't returned in *cosmic.ListWhoHasThisIpResponse so we need to fetch it
-			netparams := clientMap[client].Network.NewListNetworksParams()
-			netresp, err := clientMap[client].Network.ListNetworks(netparams)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-			vpcparams := clientMap[client].VPC.NewListVPCsParams()
-			vpcresp, err := clientMap[client].VPC.ListVPCs(vpcparams)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
+// Sort will sort WhoHasThisMacs by one or more comma separated fields, e.g. "zonename,name".
+func (macs WhoHasThisMacs) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(macs, sortBy, reverseSort)
+}
 
-			params := clientMap[client].CloudOps.NewListWhoHasThisIpParams(ipaddress)
-			resp, err := clientMap[client].CloudOps.ListWhoHasThisIp(params)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
+// ListIP returns a WhoHasThisIPs object using all configured *Client objects.
+func ListIP(ctx context.Context, clientMap map[string]*Client, ipaddress string) (WhoHasThisIPs, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		// Zonename isn't returned in *cosmic.ListWhoHasThisIpResponse so we need to fetch it
+		zoneparams := client.Zone.NewListZonesParams()
+		zoneresp, err := client.Zone.ListZones(zoneparams)
+		if err != nil {
+			return nil, err
+		}
+		zonename := zoneresp.Zones[0].Name
 
-			for _, ip := range resp.WhoHasThisIp {
-				vpcid := ""
-				vpcname := ""
+		// VPCName isn't returned in *cosmic.ListWhoHasThisIpResponse so we need to fetch it
+		netparams := client.Network.NewListNetworksParams()
+		netresp, err := client.Network.ListNetworks(netparams)
+		if err != nil {
+			return nil, err
+		}
+		vpcparams := client.VPC.NewListVPCsParams()
+		vpcresp, err := client.VPC.ListVPCs(vpcparams)
+		if err != nil {
+			return nil, err
+		}
 
-				for _, n := range netresp.Networks {
-					if n.Id == ip.Networkuuid {
-						ip.Networkname = n.Name
-						vpcid = n.Vpcid
-						break
-					}
-				}
-				for _, v := range vpcresp.VPCs {
-					if v.Id == vpcid {
-						vpcname = v.Name
-						break
-					}
-				}
+		params := client.CloudOps.NewListWhoHasThisIpParams(ipaddress)
+		resp, err := client.CloudOps.ListWhoHasThisIp(params)
+		if err != nil {
+			return nil, err
+		}
 
-				// When returning a public IP address the network name is populated with the VPC name,
-				// to avoid confusion we'll empty out the network name field so that only the VPC name
-				// contains the VPC name.
-				if ip.Networkname == ip.Vpcname {
-					ip.Networkname = ""
-					vpcname = ip.Vpcname
-				}
+		ips := []*WhoHasThisIP{}
+		for _, ip := range resp.WhoHasThisIp {
+			vpcid := ""
+			vpcname := ""
 
-				// When returning a public IP adress, the network mask is empty, so we populate it as
-				// a /32.
-				if ip.Netmask == "" {
-					ip.Netmask = "255.255.255.255"
+			for _, n := range netresp.Networks {
+				if n.Id == ip.Networkuuid {
+					ip.Networkname = n.Name
+					vpcid = n.Vpcid
+					break
 				}
+			}
+			for _, v := range vpcresp.VPCs {
+				if v.Id == vpcid {
+					vpcname = v.Name
+					break
+				}
+			}
 
-				ips = append(ips, &WhoHasThisIP{
-					WhoHasThisIp: ip,
-					Vpcname:      vpcname,
-					Zonename:     zonename,
-				})
+			// When returning a public IP address the network name is populated with the VPC name,
+			// to avoid confusion we'll empty out the network name field so that only the VPC name
+			// contains the VPC name.
+			if ip.Networkname == ip.Vpcname {
+				ip.Networkname = ""
+				vpcname = ip.Vpcname
 			}
-		}(client)
-	}
 
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
+			// When returning a public IP adress, the network mask is empty, so we populate it as
+			// a /32.
+			if ip.Netmask == "" {
+				ip.Netmask = "255.255.255.255"
+			}
 
-	select {
-	case <-finished:
-	case err := <-errChannel:
-		if err != nil {
-			return nil, err
+			ips = append(ips, &WhoHasThisIP{
+				WhoHasThisIp: ip,
+				ProfileMeta:  client.meta,
+				Vpcname:      vpcname,
+				Zonename:     zonename,
+			})
 		}
+
+		return ips, nil
+	})
+
+	ips := []*WhoHasThisIP{}
+	for _, r := range results {
+		ips = append(ips, r.([]*WhoHasThisIP)...)
 	}
 
-	return ips, nil
+	return ips, err
 }
 
-// ListMAC returns a WhoHasThisMacs object using all configured *cosmic.CosmicClient objects.
-func ListMAC(clientMap map[string]*cosmic.CosmicClient, macaddress string) (WhoHasThisMacs, error) {
-	macs := []*WhoHasThisMac{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			// Zonename isn't returned in *cosmic.ListWhoHasThisMacResponse so we need to fetch it
-			zoneparams := clientMap[client].Zone.NewListZonesParams()
-			zoneresp, err := clientMap[client].Zone.ListZones(zoneparams)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-			zonename := zoneresp.Zones[0].Name
-
-			// VPCName isn't returned in *cosmic.ListWhoHasThisMacResponse so we need to fetch it
-			netparams := clientMap[client].Network.NewListNetworksParams()
-			netresp, err := clientMap[client].Network.ListNetworks(netparams)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-			vpcparams := clientMap[client].VPC.NewListVPCsParams()
-			vpcresp, err := clientMap[client].VPC.ListVPCs(vpcparams)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
+// ListMAC returns a WhoHasThisMacs object using all configured *Client objects.
+func ListMAC(ctx context.Context, clientMap map[string]*Client, macaddress string) (WhoHasThisMacs, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		// Zonename isn't returned in *cosmic.ListWhoHasThisMacResponse so we need to fetch it
+		zoneparams := client.Zone.NewListZonesParams()
+		zoneresp, err := client.Zone.ListZones(zoneparams)
+		if err != nil {
+			return nil, err
+		}
+		zonename := zoneresp.Zones[0].Name
 
-			params := clientMap[client].CloudOps.NewListWhoHasThisMacParams()
-			params.SetMacaddress(macaddress)
-			resp, err := clientMap[client].CloudOps.ListWhoHasThisMac(params)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
+		// VPCName isn't returned in *cosmic.ListWhoHasThisMacResponse so we need to fetch it
+		netparams := client.Network.NewListNetworksParams()
+		netresp, err := client.Network.ListNetworks(netparams)
+		if err != nil {
+			return nil, err
+		}
+		vpcparams := client.VPC.NewListVPCsParams()
+		vpcresp, err := client.VPC.ListVPCs(vpcparams)
+		if err != nil {
+			return nil, err
+		}
 
-			for _, mac := range resp.WhoHasThisMac {
-				vpcid := ""
-				vpcname := ""
+		params := client.CloudOps.NewListWhoHasThisMacParams()
+		params.SetMacaddress(macaddress)
+		resp, err := client.CloudOps.ListWhoHasThisMac(params)
+		if err != nil {
+			return nil, err
+		}
 
-				for _, n := range netresp.Networks {
-					if n.Id == mac.Networkuuid {
-						mac.Networkname = n.Name
-						vpcid = n.Vpcid
-						break
-					}
-				}
-				for _, v := range vpcresp.VPCs {
-					if v.Id == vpcid {
-						vpcname = v.Name
-						break
-					}
-				}
+		macs := []*WhoHasThisMac{}
+		for _, mac := range resp.WhoHasThisMac {
+			vpcid := ""
+			vpcname := ""
 
-				// When returning a MAC address the network name is populated with the VPC name,
-				// to avoid confusion we'll empty out the network name field so that only the VPC name
-				// contains the VPC name.
-				if mac.Networkname == mac.Vpcname {
-					mac.Networkname = ""
-					vpcname = mac.Vpcname
+			for _, n := range netresp.Networks {
+				if n.Id == mac.Networkuuid {
+					mac.Networkname = n.Name
+					vpcid = n.Vpcid
+					break
 				}
-
-				// When returning a MAC adress, the network mask is empty, so we populate it as
-				// a /32.
-				if mac.Netmask == "" {
-					mac.Netmask = "255.255.255.255"
+			}
+			for _, v := range vpcresp.VPCs {
+				if v.Id == vpcid {
+					vpcname = v.Name
+					break
 				}
+			}
 
-				macs = append(macs, &WhoHasThisMac{
-					WhoHasThisMac: mac,
-					Vpcname:       vpcname,
-					Zonename:      zonename,
-				})
+			// When returning a MAC address the network name is populated with the VPC name,
+			// to avoid confusion we'll empty out the network name field so that only the VPC name
+			// contains the VPC name.
+			if mac.Networkname == mac.Vpcname {
+				mac.Networkname = ""
+				vpcname = mac.Vpcname
 			}
-		}(client)
-	}
 
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
+			// When returning a MAC adress, the network mask is empty, so we populate it as
+			// a /32.
+			if mac.Netmask == "" {
+				mac.Netmask = "255.255.255.255"
+			}
 
-	select {
-	case <-finished:
-	case err := <-errChannel:
-		if err != nil {
-			return nil, err
+			macs = append(macs, &WhoHasThisMac{
+				WhoHasThisMac: mac,
+				ProfileMeta:   client.meta,
+				Vpcname:       vpcname,
+				Zonename:      zonename,
+			})
 		}
+
+		return macs, nil
+	})
+
+	macs := []*WhoHasThisMac{}
+	for _, r := range results {
+		macs = append(macs, r.([]*WhoHasThisMac)...)
 	}
 
-	return macs, nil
+	return macs, err
 }
@@ -17,68 +17,224 @@
 package cosmic
 
 import (
+	"fmt"
+	"io"
+	"log"
+	"os"
 	"sort"
 	"strings"
+	"sync"
 
-	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
 	"github.com/forestgiant/sliceutil"
 	"github.com/shoekstra/cosmic-cli/internal/config"
 )
 
-// profileError represents a profile config error.
-type profileError struct {
-	message string
+// ProfileError represents an error returned by the API using a specific profile.
+type ProfileError struct {
+	Profile string
+	Err     error
 }
 
 // Error returns the profile error message.
-func (e profileError) Error() string {
-	return e.message
+func (e *ProfileError) Error() string {
+	return fmt.Sprintf("Error returned using profile \"%s\": %s", e.Profile, e.Err)
 }
 
-// NewAsyncClients returns a [string]*cosmic.CosmicClient map.
-func NewAsyncClients(cfg *config.Config) map[string]*cosmic.CosmicClient {
-	profiles := getProfile(cfg)
-	clientMap := make(map[string]*cosmic.CosmicClient)
+// ProfileErrors is returned when an API call fails using one or more profiles. Results returned
+// by the profiles that did not fail are still returned alongside it.
+type ProfileErrors struct {
+	Errors   []*ProfileError
+	Profiles int // The number of profiles used.
 
-	for _, profile := range profiles {
-		clientMap[profile] = cosmic.NewAsyncClient(
-			cfg.Profiles[profile].APIURL,
-			cfg.Profiles[profile].APIKey,
-			cfg.Profiles[profile].SecretKey,
-			nil,
-			int64(120),
-		)
+	mu sync.Mutex
+}
+
+// Error returns the error messages of all failed profiles.
+func (e *ProfileErrors) Error() string {
+	lines := []string{}
+	if e.Partial() {
+		lines = append(lines, fmt.Sprintf("Results are incomplete, %d of %d profiles returned an error:", len(e.failedProfiles()), e.Profiles))
+	}
+	for _, err := range e.Errors {
+		lines = append(lines, err.Error())
 	}
 
-	return clientMap
+	return strings.Join(lines, "\n")
+}
+
+// Partial returns true if at least one profile did not return an error.
+func (e *ProfileErrors) Partial() bool {
+	return len(e.failedProfiles()) < e.Profiles
 }
 
-func getProfile(cfg *config.Config) []string {
-	result := []string{}
+// Collect adds the profile errors of err to e if err is a partial *ProfileErrors and returns nil,
+// this allows results of multiple API calls to be used even when some profiles failed. Any other
+// error is returned as is.
+func (e *ProfileErrors) Collect(err error) error {
+	pe, ok := err.(*ProfileErrors)
+	if !ok || !pe.Partial() {
+		return err
+	}
+
+	e.mu.Lock()
+	if pe.Profiles > e.Profiles {
+		e.Profiles = pe.Profiles
+	}
+	e.mu.Unlock()
+
+	for _, err := range pe.Errors {
+		e.add(err.Profile, err.Err)
+	}
+
+	return nil
+}
 
-	for p := range cfg.Profiles {
-		result = append(result, p)
+// ErrorOrNil returns e if it contains any errors, or nil otherwise.
+func (e *ProfileErrors) ErrorOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
 	}
 
-	if cfg.Profile != "" {
-		profiles := strings.Split(cfg.Profile, ",")
+	sort.SliceStable(e.Errors, func(i, j int) bool { return e.Errors[i].Profile < e.Errors[j].Profile })
+
+	return e
+}
+
+// add records an error returned using profile; it is safe for concurrent use.
+func (e *ProfileErrors) add(profile string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, pe := range e.Errors {
+		if pe.Profile == profile && pe.Err.Error() == err.Error() {
+			return
+		}
+	}
+	e.Errors = append(e.Errors, &ProfileError{Profile: profile, Err: err})
+}
 
-		// Check that the specified profiles actually exist
-		for _, p := range profiles {
-			cfg.ValidProfile(p)
+func (e *ProfileErrors) failedProfiles() []string {
+	profiles := []string{}
+	for _, err := range e.Errors {
+		if !sliceutil.Contains(profiles, err.Profile) {
+			profiles = append(profiles, err.Profile)
 		}
+	}
+
+	return profiles
+}
 
-		// Remove any profiles out of scope
-		for i := len(result) - 1; i >= 0; i-- {
-			r := result[i]
+// NewAsyncClients returns a [string]*Client map containing a client for every selected profile.
+// The clients share a pool that limits the number of profiles used concurrently to
+// cfg.Parallelism; the timeout of a profile defaults to cfg.Timeout. Responses are cached for
+// cfg.CacheTTL unless cfg.NoCache is set and API calls are logged if cfg.Debug or cfg.Trace is
+// set. API calls are recorded to cfg.Record if set, or replayed from cfg.Replay instead of
+// calling the API.
+func NewAsyncClients(cfg *config.Config) map[string]*Client {
+	return defaultRegistry.NewAsyncClients(cfg)
+}
+
+// NewAsyncClients is like the NewAsyncClients function, but the clients share rate limiters, log
+// files and recordings with other clients created using r only.
+func (r *Registry) NewAsyncClients(cfg *config.Config) map[string]*Client {
+	// The selection is validated by config.New, so any error has been returned before.
+	profiles, _ := cfg.SelectProfiles()
+	clientMap := make(map[string]*Client)
+
+	var pool chan struct{}
+	if cfg.Parallelism > 0 {
+		pool = make(chan struct{}, cfg.Parallelism)
+	}
+
+	// Caching is disabled when the cache directory can't be determined. With cfg.NoCache the
+	// cache is still cleared by API calls that may change something.
+	var cache *CacheOptions
+	if dir, err := config.CacheDir(); err == nil {
+		cache = &CacheOptions{Dir: dir, TTL: cfg.CacheTTL, Read: !cfg.Refresh}
+		if cfg.NoCache {
+			cache.TTL = 0
+		}
+	}
 
-			if !sliceutil.Contains(profiles, r) {
-				result = append(result[:i], result[i+1:]...)
+	var debug *DebugOptions
+	if cfg.Debug || cfg.Trace {
+		var w io.Writer = os.Stderr
+		if cfg.LogFile != "" {
+			f, err := r.openLogFile(cfg.LogFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error opening log file, logging to stderr instead: %s\n", err)
+			} else {
+				w = f
 			}
 		}
+		debug = &DebugOptions{Logger: log.New(w, "", log.LstdFlags|log.Lmicroseconds), Trace: cfg.Trace}
+	}
+
+	// Every profile fails when the recording can't be opened.
+	var record *recorder
+	var replay *replayer
+	var recordErr error
+	switch {
+	case cfg.Replay != "":
+		replay, recordErr = r.openReplayer(cfg.Replay)
+		cache = nil
+	case cfg.Record != "":
+		record, recordErr = r.openRecorder(cfg.Record)
 	}
 
-	sort.Strings(result)
+	for _, profile := range profiles {
+		p := cfg.Profiles[profile]
+
+		opts := clientOptions{
+			cache:     cache,
+			debug:     debug,
+			rateLimit: cfg.RateLimit,
+			retry: RetryPolicy{
+				Attempts: cfg.RetryAttempts,
+				Backoff:  cfg.RetryBackoff,
+				Jitter:   cfg.RetryJitter,
+			},
+			meta:     ProfileMeta{ProfileDisplayName: p.DisplayName, Environment: p.Environment, Region: p.Region},
+			record:   record,
+			registry: r,
+			replay:   replay,
+			timeout:  cfg.Timeout,
+			pool:     pool,
+		}
+		if p.RateLimit > 0 {
+			opts.rateLimit = p.RateLimit
+		}
+		if p.Timeout > 0 {
+			opts.timeout = p.Timeout
+		}
+
+		if recordErr != nil {
+			clientMap[profile] = &Client{Profile: profile, err: recordErr}
+			continue
+		}
+
+		// Replayed profiles don't call the API, so they don't need credentials.
+		if replay != nil {
+			clientMap[profile] = newClient(profile, p.APIURL, "", "", opts)
+			continue
+		}
+
+		// Credentials are only resolved for the profiles used, as resolving them may require user
+		// input. A profile with invalid credentials, TLS or proxy settings fails when it is used.
+		apiKey, secretKey, err := p.Credentials()
+		if err == nil {
+			opts.tlsConfig, err = p.TLSConfig()
+		}
+		if err == nil {
+			opts.proxy, err = p.ProxyURL()
+		}
+		if err != nil {
+			clientMap[profile] = &Client{Profile: profile, err: err}
+			continue
+		}
 
-	return result
+		clientMap[profile] = newClient(profile, p.APIURL, apiKey, secretKey, opts)
+	}
+
+	return clientMap
 }
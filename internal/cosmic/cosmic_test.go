@@ -0,0 +1,144 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/config"
+)
+
+func ExampleProfileErrors() {
+	errs := &ProfileErrors{Profiles: 3}
+	errs.add("zone2", errors.New("connection refused"))
+	errs.add("zone1", errors.New("connection refused"))
+	err := errs.ErrorOrNil()
+
+	fmt.Println(errs.Partial())
+	fmt.Println(err)
+
+	// Output:
+	// true
+	// Results are incomplete, 2 of 3 profiles returned an error:
+	// Error returned using profile "zone1": connection refused
+	// Error returned using profile "zone2": connection refused
+}
+
+func ExampleProfileErrors_Collect() {
+	failures := &ProfileErrors{}
+
+	partial := &ProfileErrors{Profiles: 2}
+	partial.add("zone1", errors.New("connection refused"))
+	fmt.Println(failures.Collect(partial))
+
+	total := &ProfileErrors{Profiles: 1}
+	total.add("zone1", errors.New("connection refused"))
+	fmt.Println(failures.Collect(total))
+
+	fmt.Println(failures.ErrorOrNil())
+
+	// Output:
+	// <nil>
+	// Error returned using profile "zone1": connection refused
+	// Results are incomplete, 1 of 2 profiles returned an error:
+	// Error returned using profile "zone1": connection refused
+}
+
+func TestNewAsyncClientsProfileSettings(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"listvirtualmachinesresponse":{"count":1,"virtualmachine":[{"id":"1","name":"web1"}]}}`)
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "cosmic-cli-cosmic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		NoCache: true,
+		Profiles: map[string]config.Profile{
+			"ams1": {APIURL: ts.URL, APIKey: "key", SecretKey: "secret", CAFile: caFile, DisplayName: "Amsterdam 1", Environment: "production", Region: "eu-west"},
+			"ams2": {APIURL: ts.URL, APIKey: "key", SecretKey: "secret", InsecureSkipVerify: true},
+			"ams3": {APIURL: ts.URL, APIKey: "key", SecretKey: "secret"},
+			"ams4": {APIURL: ts.URL, APIKey: "key", SecretKey: "secret", CAFile: filepath.Join(dir, "missing.pem")},
+		},
+	}
+
+	vms, err := ListVMs(context.Background(), NewAsyncClients(cfg))
+
+	errs, ok := err.(*ProfileErrors)
+	if !ok || len(errs.Errors) != 2 || errs.Errors[0].Profile != "ams3" || errs.Errors[1].Profile != "ams4" {
+		t.Fatalf("ListVMs() returned error %v, want errors for the untrusted ams3 and the missing CA of ams4", err)
+	}
+
+	if len(vms) != 2 {
+		t.Fatalf("ListVMs() returned %d VMs, want 2", len(vms))
+	}
+	want := ProfileMeta{Profile: "ams1", ProfileDisplayName: "Amsterdam 1", Environment: "production", Region: "eu-west"}
+	if vms[0].ProfileMeta != want {
+		t.Errorf("ListVMs() returned %+v for ams1, want %+v", vms[0].ProfileMeta, want)
+	}
+	if vms[1].Profile != "ams2" {
+		t.Errorf("ListVMs() returned profile %q, want \"ams2\"", vms[1].Profile)
+	}
+}
+
+func TestSetTransport(t *testing.T) {
+	rt := &contextTransport{ctx: context.Background(), next: http.DefaultTransport}
+
+	cs := gocosmic.NewAsyncClient("https://api.test/client/api", "key", "secret", nil, 0)
+	if err := setTransport(cs, rt); err != nil {
+		t.Fatalf("setTransport() returned an error: %s", err)
+	}
+
+	// A client without a HTTP client is refused instead of dereferencing a nil pointer.
+	if err := setTransport(&gocosmic.CosmicClient{}, rt); err != errUnsupportedClient {
+		t.Errorf("setTransport() returned error %v, want %v", err, errUnsupportedClient)
+	}
+}
+
+func TestAsyncTimeout(t *testing.T) {
+	for timeout, want := range map[time.Duration]int64{
+		500 * time.Millisecond:  1,
+		time.Second:             1,
+		1500 * time.Millisecond: 2,
+		2 * time.Minute:         120,
+	} {
+		if got := asyncTimeout(timeout); got != want {
+			t.Errorf("asyncTimeout(%s) = %d, want %d", timeout, got, want)
+		}
+	}
+}
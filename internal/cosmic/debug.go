@@ -0,0 +1,123 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	h "github.com/shoekstra/cosmic-cli/internal/helper"
+)
+
+// DebugOptions configures logging of API calls.
+type DebugOptions struct {
+	Logger *log.Logger
+	Trace  bool // Also log the request parameters and response bodies.
+}
+
+// openLogFile opens path for appending, or returns the file if the registry already opened it.
+func (r *Registry) openLogFile(path string) (*os.File, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.logFiles[path]; ok {
+		return f, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	r.logFiles[path] = f
+
+	return f, nil
+}
+
+// debugTransport is a http.RoundTripper that logs every API call made using a profile. All
+// logged URLs, errors and bodies are redacted.
+type debugTransport struct {
+	next    http.RoundTripper
+	profile string
+	opts    *DebugOptions
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	command := requestCommand(req)
+	if t.opts.Trace {
+		t.opts.Logger.Printf("profile=%s method=%s command=%s request=%q", t.profile, req.Method, command, h.Redact(req.URL.String()))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		t.opts.Logger.Printf("profile=%s method=%s command=%s latency=%s error=%q", t.profile, req.Method, command, latency, h.Redact(err.Error()))
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	t.opts.Logger.Printf("profile=%s method=%s command=%s status=%d size=%d latency=%s cached=%t",
+		t.profile, req.Method, command, resp.StatusCode, len(b), latency, resp.Header.Get(cacheHeader) != "")
+	if t.opts.Trace {
+		t.opts.Logger.Printf("profile=%s method=%s command=%s response=%s", t.profile, req.Method, command, h.Redact(string(b)))
+	}
+
+	return resp, nil
+}
+
+// requestCommand returns the API command of req.
+func requestCommand(req *http.Request) string {
+	return requestParams(req).Get("command")
+}
+
+// requestParams returns the parameters of req, which are passed in the form body of POST
+// requests.
+func requestParams(req *http.Request) url.Values {
+	if q := req.URL.Query(); q.Get("command") != "" || req.GetBody == nil {
+		return q
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return url.Values{}
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return url.Values{}
+	}
+	form, err := url.ParseQuery(string(b))
+	if err != nil {
+		return url.Values{}
+	}
+
+	return form
+}
@@ -0,0 +1,49 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestDebugLogging(t *testing.T) {
+	var requests int32
+	ts := newFlakyServer(0, &requests)
+	defer ts.Close()
+
+	buf := &bytes.Buffer{}
+	opts := clientOptions{debug: &DebugOptions{Logger: log.New(buf, "", 0), Trace: true}}
+	clientMap := map[string]*Client{"nl1": newClient("nl1", ts.URL, "secretapikey", "secret", opts)}
+
+	if _, err := ListVMs(context.Background(), clientMap); err != nil {
+		t.Fatalf("ListVMs() returned an error: %s", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"profile=nl1 method=GET command=listVirtualMachines status=200", "apiKey=**redacted**", `"name":"vm1"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug log doesn't contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secretapikey") {
+		t.Errorf("debug log contains the API key:\n%s", out)
+	}
+}
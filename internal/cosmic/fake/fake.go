@@ -0,0 +1,138 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package fake implements the Cosmic API services used by cosmic-cli in memory, so commands and
+// library functions can be tested end to end without an API endpoint.
+package fake
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+)
+
+// Backend holds the resources of a single fake API endpoint. Resources are returned as is by the
+// list calls, filtered by the parameters cosmic-cli uses. The zero value is an endpoint without any
+// resources; most commands expect at least one zone to exist.
+type Backend struct {
+	ACLLists          []*gocosmic.NetworkACLList
+	ACLRules          []*gocosmic.NetworkACL
+	Networks          []*gocosmic.Network
+	PrivateGateways   []*gocosmic.PrivateGateway
+	PublicIPAddresses []*gocosmic.PublicIpAddress
+	StaticRoutes      []*gocosmic.StaticRoute
+	VirtualMachines   []*gocosmic.VirtualMachine
+	VPCs              []*gocosmic.VPC
+	WhoHasThisIPs     []*gocosmic.WhoHasThisIp
+	WhoHasThisMacs    []*gocosmic.WhoHasThisMac
+	Zones             []*gocosmic.Zone
+
+	// Err is returned by every API call when set, e.g. to test how API errors are handled.
+	Err error
+
+	mu     sync.Mutex
+	calls  []string
+	nextID int
+}
+
+// Services returns the API services of b.
+func (b *Backend) Services() cosmic.Services {
+	return cosmic.Services{
+		CloudOps:        &cloudOpsService{b},
+		Network:         &networkService{b},
+		NetworkACL:      &networkACLService{b},
+		PublicIPAddress: &publicIPAddressService{b},
+		VirtualMachine:  &virtualMachineService{b},
+		VPC:             &vpcService{b},
+		Zone:            &zoneService{b},
+	}
+}
+
+// Calls returns the names of the API calls made to b, in the order they were made.
+func (b *Backend) Calls() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]string{}, b.calls...)
+}
+
+// record records an API call and returns the error it should fail with, if any. The caller must
+// hold b.mu.
+func (b *Backend) record(name string) error {
+	b.calls = append(b.calls, name)
+
+	return b.Err
+}
+
+// newID returns a new unique resource id. The caller must hold b.mu.
+func (b *Backend) newID() string {
+	b.nextID++
+
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", b.nextID)
+}
+
+// Clients returns a client for each profile using the fake API of its backend.
+func Clients(backends map[string]*Backend) map[string]*cosmic.Client {
+	clientMap := make(map[string]*cosmic.Client)
+	for profile, b := range backends {
+		clientMap[profile] = cosmic.NewClient(profile, b.Services())
+	}
+
+	return clientMap
+}
+
+// param returns the value of parameter key set on p, a pointer to a go-cosmic params struct. The
+// parameters are unexported, so they are read using reflection.
+func param(p interface{}, key string) string {
+	m := reflect.ValueOf(p).Elem().FieldByName("p")
+	if !m.IsValid() || m.IsNil() {
+		return ""
+	}
+
+	v := m.MapIndex(reflect.ValueOf(key))
+	if !v.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprint(v)
+}
+
+// APIError is an error returned by the Cosmic API; its message is formatted the same way go-cosmic
+// formats errors returned by the API.
+type APIError struct {
+	ErrorCode   int    `json:"errorcode"`
+	CSErrorCode int    `json:"cserrorcode"`
+	ErrorText   string `json:"errortext"`
+}
+
+// Error returns the API error message.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Cosmic API error %d (CSExceptionErrorCode: %d): %s", e.ErrorCode, e.CSErrorCode, e.ErrorText)
+}
+
+// NotFoundError returns the error the Cosmic API returns when command is called with the id of a
+// resource that doesn't exist.
+func NotFoundError(command, id string) *APIError {
+	return &APIError{
+		ErrorCode:   431,
+		CSErrorCode: 9999,
+		ErrorText:   fmt.Sprintf("Unable to execute API command %s due to invalid value. Invalid parameter id value=%s due to incorrect long value format, or entity does not exist or due to incorrect parameter annotation for the field in api cmd class.", strings.ToLower(command), id),
+	}
+}
@@ -0,0 +1,114 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic/fake"
+)
+
+func TestVPCRoutes(t *testing.T) {
+	ams := &fake.Backend{VPCs: []*gocosmic.VPC{{Id: "vpc-1", Name: "web"}}}
+	fra := &fake.Backend{VPCs: []*gocosmic.VPC{{Id: "vpc-2", Name: "web"}}}
+	clientMap := fake.Clients(map[string]*fake.Backend{"ams": ams, "fra": fra})
+	ctx := context.Background()
+
+	// The VPC only exists using profile "ams", the error returned using "fra" is ignored.
+	if err := cosmic.CreateVPCRoute(ctx, clientMap, "vpc-1", "10.0.0.1", "192.168.1.0/24"); err != nil {
+		t.Fatalf("CreateVPCRoute() error = %v", err)
+	}
+	if len(ams.StaticRoutes) != 1 || len(fra.StaticRoutes) != 0 {
+		t.Fatalf("CreateVPCRoute() created %d and %d routes, want 1 and 0", len(ams.StaticRoutes), len(fra.StaticRoutes))
+	}
+
+	routes, err := cosmic.ListVPCRoutes(ctx, clientMap, "vpc-1")
+	if err != nil {
+		t.Fatalf("ListVPCRoutes() error = %v", err)
+	}
+	if len(routes) != 1 || routes[0].Cidr != "192.168.1.0/24" || routes[0].Nexthop != "10.0.0.1" || routes[0].Profile != "ams" {
+		t.Fatalf("ListVPCRoutes() = %+v, want the created route", routes)
+	}
+
+	if err := cosmic.DeleteVPCRoute(ctx, clientMap, routes[0].Id); err != nil {
+		t.Fatalf("DeleteVPCRoute() error = %v", err)
+	}
+	if len(ams.StaticRoutes) != 0 {
+		t.Errorf("DeleteVPCRoute() left %d routes, want 0", len(ams.StaticRoutes))
+	}
+
+	want := []string{"CreateStaticRoute", "ListStaticRoutes", "DeleteStaticRoute"}
+	if got := ams.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %v, want %v", got, want)
+	}
+}
+
+func TestVPCGetAllByName(t *testing.T) {
+	clientMap := fake.Clients(map[string]*fake.Backend{
+		"ams": {VPCs: []*gocosmic.VPC{{Id: "vpc-1", Name: "web"}, {Id: "vpc-2", Name: "db"}}},
+		"fra": {VPCs: []*gocosmic.VPC{{Id: "vpc-3", Name: "db"}}},
+	})
+
+	vpcs, err := cosmic.VPCGetAllByName(context.Background(), clientMap, "web")
+	if err != nil {
+		t.Fatalf("VPCGetAllByName() error = %v", err)
+	}
+	if len(vpcs) != 1 || vpcs[0].Id != "vpc-1" {
+		t.Errorf("VPCGetAllByName() = %+v, want VPC vpc-1", vpcs)
+	}
+}
+
+func TestListIP(t *testing.T) {
+	clientMap := fake.Clients(map[string]*fake.Backend{
+		"ams": {
+			Zones: []*gocosmic.Zone{{Id: "1", Name: "ams1"}},
+			WhoHasThisIPs: []*gocosmic.WhoHasThisIp{
+				{Ipaddress: "10.0.0.10", Virtualmachinename: "web1"},
+				{Ipaddress: "10.0.0.11", Virtualmachinename: "web2"},
+			},
+		},
+	})
+
+	ips, err := cosmic.ListIP(context.Background(), clientMap, "10.0.0.10")
+	if err != nil {
+		t.Fatalf("ListIP() error = %v", err)
+	}
+	if len(ips) != 1 || ips[0].Virtualmachinename != "web1" || ips[0].Zonename != "ams1" {
+		t.Errorf("ListIP() = %+v, want web1 in zone ams1", ips)
+	}
+}
+
+func TestBackendErr(t *testing.T) {
+	clientMap := fake.Clients(map[string]*fake.Backend{
+		"ams": {VirtualMachines: []*gocosmic.VirtualMachine{{Id: "1", Name: "web1"}}},
+		"fra": {Err: errors.New("connection refused")},
+	})
+
+	vms, err := cosmic.ListVMs(context.Background(), clientMap)
+	errs, ok := err.(*cosmic.ProfileErrors)
+	if !ok || !errs.Partial() {
+		t.Fatalf("ListVMs() error = %v, want a partial *cosmic.ProfileErrors", err)
+	}
+	if len(vms) != 1 || vms[0].Name != "web1" {
+		t.Errorf("ListVMs() = %+v, want web1", vms)
+	}
+}
@@ -0,0 +1,371 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package fake
+
+import (
+	"fmt"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+)
+
+// The New*Params methods of the go-cosmic services don't use their client, so the fake services
+// use zero value go-cosmic services to create params.
+
+type cloudOpsService struct{ b *Backend }
+
+func (s *cloudOpsService) NewListWhoHasThisIpParams(ipaddress string) *gocosmic.ListWhoHasThisIpParams {
+	return (&gocosmic.CloudOpsService{}).NewListWhoHasThisIpParams(ipaddress)
+}
+
+func (s *cloudOpsService) ListWhoHasThisIp(p *gocosmic.ListWhoHasThisIpParams) (*gocosmic.ListWhoHasThisIpResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListWhoHasThisIp"); err != nil {
+		return nil, err
+	}
+
+	resp := &gocosmic.ListWhoHasThisIpResponse{WhoHasThisIp: []*gocosmic.WhoHasThisIp{}}
+	for _, r := range s.b.WhoHasThisIPs {
+		if r.Ipaddress == param(p, "ipaddress") {
+			resp.WhoHasThisIp = append(resp.WhoHasThisIp, r)
+		}
+	}
+	resp.Count = len(resp.WhoHasThisIp)
+
+	return resp, nil
+}
+
+func (s *cloudOpsService) NewListWhoHasThisMacParams() *gocosmic.ListWhoHasThisMacParams {
+	return (&gocosmic.CloudOpsService{}).NewListWhoHasThisMacParams()
+}
+
+func (s *cloudOpsService) ListWhoHasThisMac(p *gocosmic.ListWhoHasThisMacParams) (*gocosmic.ListWhoHasThisMacResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListWhoHasThisMac"); err != nil {
+		return nil, err
+	}
+
+	mac := param(p, "macaddress")
+	resp := &gocosmic.ListWhoHasThisMacResponse{WhoHasThisMac: []*gocosmic.WhoHasThisMac{}}
+	for _, r := range s.b.WhoHasThisMacs {
+		if mac == "" || r.Macaddress == mac {
+			resp.WhoHasThisMac = append(resp.WhoHasThisMac, r)
+		}
+	}
+	resp.Count = len(resp.WhoHasThisMac)
+
+	return resp, nil
+}
+
+type networkService struct{ b *Backend }
+
+func (s *networkService) NewListNetworksParams() *gocosmic.ListNetworksParams {
+	return (&gocosmic.NetworkService{}).NewListNetworksParams()
+}
+
+func (s *networkService) ListNetworks(p *gocosmic.ListNetworksParams) (*gocosmic.ListNetworksResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListNetworks"); err != nil {
+		return nil, err
+	}
+
+	resp := &gocosmic.ListNetworksResponse{Networks: append([]*gocosmic.Network{}, s.b.Networks...)}
+	resp.Count = len(resp.Networks)
+
+	return resp, nil
+}
+
+type networkACLService struct{ b *Backend }
+
+func (s *networkACLService) GetNetworkACLListByID(id string, opts ...gocosmic.OptionFunc) (*gocosmic.NetworkACLList, int, error) {
+	p := s.NewListNetworkACLListsParams()
+	p.SetId(id)
+
+	l, err := s.ListNetworkACLLists(p)
+	if err != nil {
+		return nil, -1, err
+	}
+	if l.Count == 0 {
+		return nil, l.Count, fmt.Errorf("No match found for %s: %+v", id, l)
+	}
+
+	return l.NetworkACLLists[0], l.Count, nil
+}
+
+func (s *networkACLService) NewListNetworkACLListsParams() *gocosmic.ListNetworkACLListsParams {
+	return (&gocosmic.NetworkACLService{}).NewListNetworkACLListsParams()
+}
+
+func (s *networkACLService) ListNetworkACLLists(p *gocosmic.ListNetworkACLListsParams) (*gocosmic.ListNetworkACLListsResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListNetworkACLLists"); err != nil {
+		return nil, err
+	}
+
+	id := param(p, "id")
+	resp := &gocosmic.ListNetworkACLListsResponse{NetworkACLLists: []*gocosmic.NetworkACLList{}}
+	for _, r := range s.b.ACLLists {
+		if id == "" || r.Id == id {
+			resp.NetworkACLLists = append(resp.NetworkACLLists, r)
+		}
+	}
+	resp.Count = len(resp.NetworkACLLists)
+
+	return resp, nil
+}
+
+func (s *networkACLService) NewListNetworkACLsParams() *gocosmic.ListNetworkACLsParams {
+	return (&gocosmic.NetworkACLService{}).NewListNetworkACLsParams()
+}
+
+func (s *networkACLService) ListNetworkACLs(p *gocosmic.ListNetworkACLsParams) (*gocosmic.ListNetworkACLsResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListNetworkACLs"); err != nil {
+		return nil, err
+	}
+
+	aclid := param(p, "aclid")
+	resp := &gocosmic.ListNetworkACLsResponse{NetworkACLs: []*gocosmic.NetworkACL{}}
+	for _, r := range s.b.ACLRules {
+		if aclid == "" || r.Aclid == aclid {
+			resp.NetworkACLs = append(resp.NetworkACLs, r)
+		}
+	}
+	resp.Count = len(resp.NetworkACLs)
+
+	return resp, nil
+}
+
+type publicIPAddressService struct{ b *Backend }
+
+func (s *publicIPAddressService) NewListPublicIpAddressesParams() *gocosmic.ListPublicIpAddressesParams {
+	return (&gocosmic.PublicIPAddressService{}).NewListPublicIpAddressesParams()
+}
+
+func (s *publicIPAddressService) ListPublicIpAddresses(p *gocosmic.ListPublicIpAddressesParams) (*gocosmic.ListPublicIpAddressesResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListPublicIpAddresses"); err != nil {
+		return nil, err
+	}
+
+	resp := &gocosmic.ListPublicIpAddressesResponse{PublicIpAddresses: append([]*gocosmic.PublicIpAddress{}, s.b.PublicIPAddresses...)}
+	resp.Count = len(resp.PublicIpAddresses)
+
+	return resp, nil
+}
+
+type virtualMachineService struct{ b *Backend }
+
+func (s *virtualMachineService) NewListVirtualMachinesParams() *gocosmic.ListVirtualMachinesParams {
+	return (&gocosmic.VirtualMachineService{}).NewListVirtualMachinesParams()
+}
+
+func (s *virtualMachineService) ListVirtualMachines(p *gocosmic.ListVirtualMachinesParams) (*gocosmic.ListVirtualMachinesResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListVirtualMachines"); err != nil {
+		return nil, err
+	}
+
+	resp := &gocosmic.ListVirtualMachinesResponse{VirtualMachines: append([]*gocosmic.VirtualMachine{}, s.b.VirtualMachines...)}
+	resp.Count = len(resp.VirtualMachines)
+
+	return resp, nil
+}
+
+type vpcService struct{ b *Backend }
+
+func (s *vpcService) GetVPCByID(id string, opts ...gocosmic.OptionFunc) (*gocosmic.VPC, int, error) {
+	p := s.NewListVPCsParams()
+	p.SetId(id)
+
+	return s.getVPC(id, p)
+}
+
+func (s *vpcService) GetVPCByName(name string, opts ...gocosmic.OptionFunc) (*gocosmic.VPC, int, error) {
+	p := s.NewListVPCsParams()
+	p.SetName(name)
+
+	return s.getVPC(name, p)
+}
+
+// getVPC returns the single VPC listed using p, like the go-cosmic helpers do.
+func (s *vpcService) getVPC(key string, p *gocosmic.ListVPCsParams) (*gocosmic.VPC, int, error) {
+	l, err := s.ListVPCs(p)
+	if err != nil {
+		return nil, -1, err
+	}
+	if l.Count == 0 {
+		return nil, l.Count, fmt.Errorf("No match found for %s: %+v", key, l)
+	}
+	if l.Count > 1 {
+		return nil, l.Count, fmt.Errorf("There is more then one result for VPC UUID: %s!", key)
+	}
+
+	return l.VPCs[0], l.Count, nil
+}
+
+func (s *vpcService) NewListVPCsParams() *gocosmic.ListVPCsParams {
+	return (&gocosmic.VPCService{}).NewListVPCsParams()
+}
+
+func (s *vpcService) ListVPCs(p *gocosmic.ListVPCsParams) (*gocosmic.ListVPCsResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListVPCs"); err != nil {
+		return nil, err
+	}
+
+	id, name := param(p, "id"), param(p, "name")
+	resp := &gocosmic.ListVPCsResponse{VPCs: []*gocosmic.VPC{}}
+	for _, r := range s.b.VPCs {
+		if (id == "" || r.Id == id) && (name == "" || r.Name == name) {
+			resp.VPCs = append(resp.VPCs, r)
+		}
+	}
+	resp.Count = len(resp.VPCs)
+
+	return resp, nil
+}
+
+func (s *vpcService) NewListPrivateGatewaysParams() *gocosmic.ListPrivateGatewaysParams {
+	return (&gocosmic.VPCService{}).NewListPrivateGatewaysParams()
+}
+
+func (s *vpcService) ListPrivateGateways(p *gocosmic.ListPrivateGatewaysParams) (*gocosmic.ListPrivateGatewaysResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListPrivateGateways"); err != nil {
+		return nil, err
+	}
+
+	resp := &gocosmic.ListPrivateGatewaysResponse{PrivateGateways: append([]*gocosmic.PrivateGateway{}, s.b.PrivateGateways...)}
+	resp.Count = len(resp.PrivateGateways)
+
+	return resp, nil
+}
+
+func (s *vpcService) NewListStaticRoutesParams() *gocosmic.ListStaticRoutesParams {
+	return (&gocosmic.VPCService{}).NewListStaticRoutesParams()
+}
+
+func (s *vpcService) ListStaticRoutes(p *gocosmic.ListStaticRoutesParams) (*gocosmic.ListStaticRoutesResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListStaticRoutes"); err != nil {
+		return nil, err
+	}
+
+	vpcid := param(p, "vpcid")
+	resp := &gocosmic.ListStaticRoutesResponse{StaticRoutes: []*gocosmic.StaticRoute{}}
+	for _, r := range s.b.StaticRoutes {
+		if vpcid == "" || r.Vpcid == vpcid {
+			resp.StaticRoutes = append(resp.StaticRoutes, r)
+		}
+	}
+	resp.Count = len(resp.StaticRoutes)
+
+	return resp, nil
+}
+
+func (s *vpcService) NewCreateStaticRouteParams(cidr string, nexthop string, vpcid string) *gocosmic.CreateStaticRouteParams {
+	return (&gocosmic.VPCService{}).NewCreateStaticRouteParams(cidr, nexthop, vpcid)
+}
+
+func (s *vpcService) CreateStaticRoute(p *gocosmic.CreateStaticRouteParams) (*gocosmic.CreateStaticRouteResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("CreateStaticRoute"); err != nil {
+		return nil, err
+	}
+
+	vpcid := param(p, "vpcid")
+	found := false
+	for _, v := range s.b.VPCs {
+		if v.Id == vpcid {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, NotFoundError("createStaticRoute", vpcid)
+	}
+
+	r := &gocosmic.StaticRoute{
+		Cidr:    param(p, "cidr"),
+		Id:      s.b.newID(),
+		Nexthop: param(p, "nexthop"),
+		State:   "Active",
+		Vpcid:   vpcid,
+	}
+	s.b.StaticRoutes = append(s.b.StaticRoutes, r)
+
+	return &gocosmic.CreateStaticRouteResponse{
+		JobID:   s.b.newID(),
+		Cidr:    r.Cidr,
+		Id:      r.Id,
+		Nexthop: r.Nexthop,
+		State:   r.State,
+		Vpcid:   r.Vpcid,
+	}, nil
+}
+
+func (s *vpcService) NewDeleteStaticRouteParams(id string) *gocosmic.DeleteStaticRouteParams {
+	return (&gocosmic.VPCService{}).NewDeleteStaticRouteParams(id)
+}
+
+func (s *vpcService) DeleteStaticRoute(p *gocosmic.DeleteStaticRouteParams) (*gocosmic.DeleteStaticRouteResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("DeleteStaticRoute"); err != nil {
+		return nil, err
+	}
+
+	id := param(p, "id")
+	for i, r := range s.b.StaticRoutes {
+		if r.Id == id {
+			s.b.StaticRoutes = append(s.b.StaticRoutes[:i], s.b.StaticRoutes[i+1:]...)
+			return &gocosmic.DeleteStaticRouteResponse{JobID: s.b.newID(), Success: true}, nil
+		}
+	}
+
+	return nil, NotFoundError("deleteStaticRoute", id)
+}
+
+type zoneService struct{ b *Backend }
+
+func (s *zoneService) NewListZonesParams() *gocosmic.ListZonesParams {
+	return (&gocosmic.ZoneService{}).NewListZonesParams()
+}
+
+func (s *zoneService) ListZones(p *gocosmic.ListZonesParams) (*gocosmic.ListZonesResponse, error) {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if err := s.b.record("ListZones"); err != nil {
+		return nil, err
+	}
+
+	resp := &gocosmic.ListZonesResponse{Zones: append([]*gocosmic.Zone{}, s.b.Zones...)}
+	resp.Count = len(resp.Zones)
+
+	return resp, nil
+}
@@ -0,0 +1,103 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// profileFunc is called by fanOut for a single profile and returns the results of that profile.
+// All API calls made using client are bound to the context passed to fanOut.
+type profileFunc func(profile string, client *Client) (interface{}, error)
+
+// fanOut calls fn concurrently for every client in clientMap, limited by the pool shared by the
+// clients. The results of all profiles that did not return an error are returned in order of
+// profile name, nil results are left out and errors are returned as a *ProfileErrors. A profile
+// fails when its calls take longer than the timeout of its client, which starts once it gets a
+// slot in the pool, or when ctx is cancelled.
+func fanOut(ctx context.Context, clientMap map[string]*Client, fn profileFunc) ([]interface{}, error) {
+	profiles := []string{}
+	for p := range clientMap {
+		profiles = append(profiles, p)
+	}
+	sort.Strings(profiles)
+
+	// Every goroutine writes to its own index, so results need no locking.
+	results := make([]interface{}, len(profiles))
+	errs := &ProfileErrors{Profiles: len(profiles)}
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(profiles))
+
+	for i, profile := range profiles {
+		go func(i int, profile string) {
+			defer wg.Done()
+
+			client := clientMap[profile]
+			if client.err != nil {
+				errs.add(profile, client.err)
+				return
+			}
+
+			// Time spent waiting for a slot in the pool doesn't count against the timeout.
+			if err := client.acquire(ctx); err != nil {
+				errs.add(profile, contextError(ctx, client.Timeout))
+				return
+			}
+			defer client.release()
+
+			pctx, cancel := ctx, context.CancelFunc(func() {})
+			if client.Timeout > 0 {
+				pctx, cancel = context.WithTimeout(ctx, client.Timeout)
+			}
+			defer cancel()
+
+			r, err := fn(profile, client.withContext(pctx))
+			if err != nil {
+				if pctx.Err() != nil {
+					err = contextError(pctx, client.Timeout)
+				}
+				errs.add(profile, err)
+				return
+			}
+			results[i] = r
+		}(i, profile)
+	}
+	wg.Wait()
+
+	r := []interface{}{}
+	for _, result := range results {
+		if result != nil {
+			r = append(r, result)
+		}
+	}
+
+	return r, errs.ErrorOrNil()
+}
+
+// contextError returns a readable error explaining why ctx is done.
+func contextError(ctx context.Context, timeout time.Duration) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("Timed out after %s", timeout)
+	}
+	return errors.New("Cancelled")
+}
@@ -0,0 +1,199 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestServer returns a test server that returns a single virtual machine named after profile,
+// or an error if fail is true.
+func newTestServer(profile string, fail bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"listvirtualmachinesresponse":{"errorcode":401,"errortext":"unable to verify user credentials"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"listvirtualmachinesresponse":{"count":1,"virtualmachine":[{"id":"%s","name":"%s"}]}}`, profile, profile)
+	}))
+}
+
+// newTestClients returns a client per profile, each backed by its own test server. Profiles in
+// failing return an error. The returned function closes all test servers.
+func newTestClients(profiles []string, failing ...string) (map[string]*Client, func()) {
+	clientMap := map[string]*Client{}
+	servers := []*httptest.Server{}
+
+	for _, p := range profiles {
+		fail := false
+		for _, f := range failing {
+			fail = fail || f == p
+		}
+
+		ts := newTestServer(p, fail)
+		servers = append(servers, ts)
+
+		clientMap[p] = newClient(p, ts.URL, "key", "secret", clientOptions{})
+	}
+
+	return clientMap, func() {
+		for _, ts := range servers {
+			ts.Close()
+		}
+	}
+}
+
+func TestFanOutOrder(t *testing.T) {
+	profiles := []string{"nl1", "de1", "be1", "nl2", "uk1", "fr1", "ch1", "at1"}
+	clientMap, closeServers := newTestClients(profiles)
+	defer closeServers()
+
+	vms, err := ListVMs(context.Background(), clientMap)
+	if err != nil {
+		t.Fatalf("ListVMs() returned an error: %s", err)
+	}
+
+	want := []string{"at1", "be1", "ch1", "de1", "fr1", "nl1", "nl2", "uk1"}
+	if len(vms) != len(want) {
+		t.Fatalf("ListVMs() returned %d instances, want %d", len(vms), len(want))
+	}
+	for i, vm := range vms {
+		if vm.Name != want[i] {
+			t.Errorf("ListVMs()[%d] = %s, want %s", i, vm.Name, want[i])
+		}
+	}
+}
+
+func TestFanOutPartialFailure(t *testing.T) {
+	clientMap, closeServers := newTestClients([]string{"nl1", "nl2", "nl3"}, "nl2")
+	defer closeServers()
+
+	vms, err := ListVMs(context.Background(), clientMap)
+	if len(vms) != 2 || vms[0].Name != "nl1" || vms[1].Name != "nl3" {
+		t.Errorf("ListVMs() did not return the instances of the healthy profiles")
+	}
+
+	pe, ok := err.(*ProfileErrors)
+	if !ok || !pe.Partial() || len(pe.Errors) != 1 || pe.Errors[0].Profile != "nl2" {
+		t.Errorf("ListVMs() error = %v, want a partial error for profile nl2", err)
+	}
+}
+
+func TestFanOutParallelism(t *testing.T) {
+	clientMap, closeServers := newTestClients([]string{"nl1", "nl2", "nl3", "nl4", "nl5", "nl6"})
+	defer closeServers()
+
+	pool := make(chan struct{}, 2)
+	for _, c := range clientMap {
+		c.pool = pool
+	}
+
+	var running, max int32
+	_, err := fanOut(context.Background(), clientMap, func(profile string, client *Client) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("fanOut() returned an error: %s", err)
+	}
+
+	if max > 2 {
+		t.Errorf("fanOut() used %d profiles concurrently, want at most 2", max)
+	}
+}
+
+func TestFanOutTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	clientMap := map[string]*Client{
+		"nl1": newClient("nl1", ts.URL, "key", "secret", clientOptions{timeout: 50 * time.Millisecond}),
+	}
+
+	start := time.Now()
+	_, err := ListVMs(context.Background(), clientMap)
+	if d := time.Since(start); d > 250*time.Millisecond {
+		t.Errorf("ListVMs() took %s, want it to time out after 50ms", d)
+	}
+
+	want := `Error returned using profile "nl1": Timed out after 50ms`
+	if err == nil || err.Error() != want {
+		t.Errorf("ListVMs() error = %v, want %s", err, want)
+	}
+}
+
+func TestFanOutTimeoutStartsAfterAcquire(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, `{"listvirtualmachinesresponse":{"count":0}}`)
+	}))
+	defer ts.Close()
+
+	// Only one profile runs at a time, so the second one waits for the first before its calls
+	// start; the wait must not count against its timeout.
+	pool := make(chan struct{}, 1)
+	clientMap := map[string]*Client{}
+	for _, p := range []string{"nl1", "nl2"} {
+		clientMap[p] = newClient(p, ts.URL, "key", "secret", clientOptions{timeout: 150 * time.Millisecond, pool: pool})
+	}
+
+	if _, err := ListVMs(context.Background(), clientMap); err != nil {
+		t.Errorf("ListVMs() returned an error: %s", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	ts := newTestServer("nl1", false)
+	defer ts.Close()
+
+	// Both clients use the same endpoint and so share the limit of 100 requests per second.
+	clientMap := map[string]*Client{
+		"nl1": newClient("nl1", ts.URL, "key", "secret", clientOptions{rateLimit: 100}),
+		"nl2": newClient("nl2", ts.URL, "key", "secret", clientOptions{rateLimit: 100}),
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := ListVMs(context.Background(), clientMap); err != nil {
+			t.Fatalf("ListVMs() returned an error: %s", err)
+		}
+	}
+
+	// The first request passes immediately, the other five are spaced out by 10ms.
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("6 requests at 100 requests per second took %s, want at least 50ms", d)
+	}
+}
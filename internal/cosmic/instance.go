@@ -17,21 +17,28 @@
 package cosmic
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"sort"
-	"strings"
-	"sync"
 
 	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
-	h "github.com/shoekstra/cosmic-cli/internal/helper"
 )
 
 // VirtualMachine embeds *cosmic.VirtualMachine to allow additional fields.
 type VirtualMachine struct {
 	*cosmic.VirtualMachine
-	Networkname string
-	Vpcname     string
+	ProfileMeta
+	Networkname string `json:"networkname,omitempty"`
+	Vpcname     string `json:"vpcname,omitempty"`
+}
+
+// FieldAliases returns field names that can be used for *VirtualMachine fields in filters and
+// output; "ipaddress" matches the IP address of any NIC and "version" is a lot prettier to print
+// and more user friendly than "laststartversion".
+func (vm *VirtualMachine) FieldAliases() map[string]string {
+	return map[string]string{
+		"ipaddress": "nic.*.ipaddress",
+		"version":   "laststartversion",
+	}
 }
 
 // VirtualMachines exists to provide helper methods for []*VirtualMachine.
@@ -68,78 +75,35 @@ func (vms VirtualMachines) FindByName(name string) ([]*VirtualMachine, error) {
 	return r, nil
 }
 
-// Sort will sort VirtualMachines by either the "ipaddress", "name" or "zonename" field.
-func (vms VirtualMachines) Sort(sortBy string, reverseSort bool) {
-	if !h.Contains([]string{"ipaddress", "name", "zonename"}, sortBy) {
-		fmt.Println("Invalid sort option provided, provide either \"ipaddress\", \"name\" or \"zonename\".")
-		os.Exit(1)
-	}
-
-	switch {
-	case strings.EqualFold(sortBy, "Ipaddress"):
-		sort.SliceStable(vms, func(i, j int) bool {
-			if reverseSort {
-				return vms[i].Nic[0].Ipaddress > vms[j].Nic[0].Ipaddress
-			}
-			return vms[i].Nic[0].Ipaddress < vms[j].Nic[0].Ipaddress
-		})
-	case strings.EqualFold(sortBy, "Name"):
-		sort.SliceStable(vms, func(i, j int) bool {
-			if reverseSort {
-				return vms[i].Name > vms[j].Name
-			}
-			return vms[i].Name < vms[j].Name
-		})
-	case strings.EqualFold(sortBy, "Zonename"):
-		sort.SliceStable(vms, func(i, j int) bool {
-			if reverseSort {
-				return vms[i].Zonename > vms[j].Zonename
-			}
-			return vms[i].Zonename < vms[j].Zonename
-		})
-	}
+// Sort will sort VirtualMachines by one or more comma separated fields, e.g. "zonename,name".
+func (vms VirtualMachines) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(vms, sortBy, reverseSort)
 }
 
-// ListVMs returns a VirtualMachines object using all configured *cosmic.CosmicClient objects.
-func ListVMs(clientMap map[string]*cosmic.CosmicClient) (VirtualMachines, error) {
-	vms := []*VirtualMachine{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			params := clientMap[client].VirtualMachine.NewListVirtualMachinesParams()
-			resp, err := clientMap[client].VirtualMachine.ListVirtualMachines(params)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-
-			for _, vm := range resp.VirtualMachines {
-				vms = append(vms, &VirtualMachine{
-					VirtualMachine: vm,
-				})
-			}
-		}(client)
-	}
-
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
-
-	select {
-	case <-finished:
-	case err := <-errChannel:
+// ListVMs returns a VirtualMachines object using all configured *Client objects.
+func ListVMs(ctx context.Context, clientMap map[string]*Client) (VirtualMachines, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.VirtualMachine.NewListVirtualMachinesParams()
+		resp, err := client.VirtualMachine.ListVirtualMachines(params)
 		if err != nil {
 			return nil, err
 		}
+
+		vms := []*VirtualMachine{}
+		for _, vm := range resp.VirtualMachines {
+			vms = append(vms, &VirtualMachine{
+				VirtualMachine: vm,
+				ProfileMeta:    client.meta,
+			})
+		}
+
+		return vms, nil
+	})
+
+	vms := []*VirtualMachine{}
+	for _, r := range results {
+		vms = append(vms, r.([]*VirtualMachine)...)
 	}
 
-	return vms, nil
+	return vms, err
 }
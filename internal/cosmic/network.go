@@ -17,8 +17,8 @@
 package cosmic
 
 import (
+	"context"
 	"fmt"
-	"sync"
 
 	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
 )
@@ -26,11 +26,17 @@ import (
 // Network embeds *cosmic.Network to allow additional fields.
 type Network struct {
 	*cosmic.Network
+	ProfileMeta
 }
 
 // Networks exists to provide helper methods for []*Network.
 type Networks []*Network
 
+// Sort will sort Networks by one or more comma separated fields, e.g. "zonename,name".
+func (n Networks) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(n, sortBy, reverseSort)
+}
+
 // FindByID looks for a Network object by ID in Networks and returns it if it exists.
 func (n Networks) FindByID(id string) ([]*Network, error) {
 	r := []*Network{}
@@ -62,46 +68,30 @@ func (n Networks) FindByName(name string) ([]*Network, error) {
 	return r, nil
 }
 
-// ListNetworks returns a Networks object using all configured *cosmic.CosmicClient objects.
-func ListNetworks(clientMap map[string]*cosmic.CosmicClient) (Networks, error) {
-	networks := []*Network{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			params := clientMap[client].Network.NewListNetworksParams()
-			resp, err := clientMap[client].Network.ListNetworks(params)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-
-			for _, n := range resp.Networks {
-				networks = append(networks, &Network{
-					Network: n,
-				})
-			}
-		}(client)
-	}
-
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
-
-	select {
-	case <-finished:
-	case err := <-errChannel:
+// ListNetworks returns a Networks object using all configured *Client objects.
+func ListNetworks(ctx context.Context, clientMap map[string]*Client) (Networks, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.Network.NewListNetworksParams()
+		resp, err := client.Network.ListNetworks(params)
 		if err != nil {
 			return nil, err
 		}
+
+		networks := []*Network{}
+		for _, n := range resp.Networks {
+			networks = append(networks, &Network{
+				Network:     n,
+				ProfileMeta: client.meta,
+			})
+		}
+
+		return networks, nil
+	})
+
+	networks := []*Network{}
+	for _, r := range results {
+		networks = append(networks, r.([]*Network)...)
 	}
 
-	return networks, nil
+	return networks, err
 }
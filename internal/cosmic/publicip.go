@@ -17,60 +17,48 @@
 package cosmic
 
 import (
-	"fmt"
-	"sync"
-
+	"context"
 	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
 )
 
 // PublicIPAddress embeds *cosmic.PublicIpAddress to allow additional fields.
 type PublicIPAddress struct {
 	*cosmic.PublicIpAddress
+	ProfileMeta
 }
 
 // PublicIPAddresses exists to provide helper methods for []*PublicIPAddress.
 type PublicIPAddresses []*PublicIPAddress
 
-// ListPublicIPAddresses returns a PublicIPAddresses object using all configured *cosmic.CosmicClient objects.
-func ListPublicIPAddresses(clientMap map[string]*cosmic.CosmicClient) (PublicIPAddresses, error) {
-	publicips := []*PublicIPAddress{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			params := clientMap[client].PublicIPAddress.NewListPublicIpAddressesParams()
-			resp, err := clientMap[client].PublicIPAddress.ListPublicIpAddresses(params)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-
-			for _, ip := range resp.PublicIpAddresses {
-				publicips = append(publicips, &PublicIPAddress{
-					PublicIpAddress: ip,
-				})
-			}
-		}(client)
-	}
-
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
+// Sort will sort PublicIPAddresses by one or more comma separated fields, e.g. "zonename,name".
+func (p PublicIPAddresses) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(p, sortBy, reverseSort)
+}
 
-	select {
-	case <-finished:
-	case err := <-errChannel:
+// ListPublicIPAddresses returns a PublicIPAddresses object using all configured *Client objects.
+func ListPublicIPAddresses(ctx context.Context, clientMap map[string]*Client) (PublicIPAddresses, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.PublicIPAddress.NewListPublicIpAddressesParams()
+		resp, err := client.PublicIPAddress.ListPublicIpAddresses(params)
 		if err != nil {
 			return nil, err
 		}
+
+		publicips := []*PublicIPAddress{}
+		for _, ip := range resp.PublicIpAddresses {
+			publicips = append(publicips, &PublicIPAddress{
+				PublicIpAddress: ip,
+				ProfileMeta:     client.meta,
+			})
+		}
+
+		return publicips, nil
+	})
+
+	publicips := []*PublicIPAddress{}
+	for _, r := range results {
+		publicips = append(publicips, r.([]*PublicIPAddress)...)
 	}
 
-	return publicips, nil
+	return publicips, err
 }
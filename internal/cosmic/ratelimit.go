@@ -0,0 +1,87 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces out calls to wait so no more than a set number of calls per second pass.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// endpointLimiter returns the rate limiter for host, creating it if it doesn't exist yet. All
+// clients using the same endpoint and registry share the limiter. The rate of an existing limiter
+// is lowered if rps is lower than its current rate.
+func (r *Registry) endpointLimiter(host string, rps float64) *rateLimiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interval := time.Duration(float64(time.Second) / rps)
+
+	l, ok := r.limiters[host]
+	if !ok {
+		l = &rateLimiter{}
+		r.limiters[host] = l
+	}
+
+	l.mu.Lock()
+	if interval > l.interval {
+		l.interval = interval
+	}
+	l.mu.Unlock()
+
+	return l
+}
+
+// wait blocks until the next call is allowed or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	return sleep(ctx, delay)
+}
+
+// rateLimitTransport is a http.RoundTripper that waits for its rate limiter before each request.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rateLimiter
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,216 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	h "github.com/shoekstra/cosmic-cli/internal/helper"
+)
+
+// recordedCall is a single API call recorded using --record. A recording contains one call per
+// line as JSON, in the order the calls finished.
+type recordedCall struct {
+	Profile string `json:"profile"`
+	Params  string `json:"params"` // Parameters of the call, without the API key and signature.
+	Status  int    `json:"status,omitempty"`
+	Body    string `json:"body,omitempty"`
+	Error   string `json:"error,omitempty"` // Error returned instead of a response.
+}
+
+// recordParams returns the parameters of req as recorded; the API key and signature are left out
+// and any other credentials are redacted.
+func recordParams(req *http.Request) string {
+	params := requestParams(req)
+	params.Del("apiKey")
+	params.Del("signature")
+
+	return h.Redact(params.Encode())
+}
+
+// recorder writes the API calls of all clients to a recording.
+type recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// openRecorder returns a recorder writing to path, which is truncated when the registry first
+// opens it; so each recording is only truncated once when multiple sets of clients are created.
+func (reg *Registry) openRecorder(path string) (*recorder, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if r, ok := reg.recorders[path]; ok {
+		return r, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	// HTML isn't escaped, so the recording can easily be reviewed before it's shared.
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	r := &recorder{enc: enc}
+	reg.recorders[path] = r
+
+	return r, nil
+}
+
+// record writes c to the recording.
+func (r *recorder) record(c *recordedCall) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.enc.Encode(c)
+}
+
+// recordTransport is a http.RoundTripper that records every API call made using a profile. The
+// responses are redacted; a failure to record a call doesn't fail the call.
+type recordTransport struct {
+	next     http.RoundTripper
+	profile  string
+	recorder *recorder
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c := &recordedCall{Profile: t.profile, Params: recordParams(req)}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		c.Error = h.Redact(err.Error())
+		t.recorder.record(c)
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	c.Status = resp.StatusCode
+	c.Body = h.Redact(string(b))
+	t.recorder.record(c)
+
+	return resp, nil
+}
+
+// replayer returns the API calls of a recording. Recorded calls are returned in order; once all
+// calls with the same profile and parameters have been returned, the last one is returned again.
+type replayer struct {
+	file  string
+	mu    sync.Mutex
+	calls []*recordedCall
+	used  []bool
+}
+
+// openReplayer returns a replayer for the recording at path. The registry returns the same
+// replayer for every set of clients, so recorded calls are only returned once.
+func (reg *Registry) openReplayer(path string) (*replayer, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if r, ok := reg.replayers[path]; ok {
+		return r, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &replayer{file: path}
+	dec := json.NewDecoder(f)
+	for {
+		c := &recordedCall{}
+		if err := dec.Decode(c); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Error reading recording %s: %s", path, err)
+		}
+		r.calls = append(r.calls, c)
+	}
+	r.used = make([]bool, len(r.calls))
+	reg.replayers[path] = r
+
+	return r, nil
+}
+
+// call returns the recorded call of profile with the given parameters.
+func (r *replayer) call(profile, params string) (*recordedCall, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, c := range r.calls {
+		if c.Profile != profile || c.Params != params {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return c, true
+		}
+		last = i
+	}
+	if last < 0 {
+		return nil, false
+	}
+
+	return r.calls[last], true
+}
+
+// replayTransport is a http.RoundTripper that returns the recorded responses of a profile instead
+// of calling the API.
+type replayTransport struct {
+	profile  string
+	replayer *replayer
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c, ok := t.replayer.call(t.profile, recordParams(req))
+	if !ok {
+		return nil, fmt.Errorf("No recorded response for command %s in %s", requestCommand(req), t.replayer.file)
+	}
+	if c.Error != "" {
+		return nil, errors.New(c.Error)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.Status, http.StatusText(c.Status)),
+		StatusCode:    c.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(c.Body))),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,80 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	var requests int32
+	ts := newFlakyServer(0, &requests)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "cosmic-cli-record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "recording.jsonl")
+
+	rec, err := NewRegistry().openRecorder(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientMap := map[string]*Client{"nl1": newClient("nl1", ts.URL, "secretapikey", "secret", clientOptions{record: rec})}
+	if _, err := ListVMs(context.Background(), clientMap); err != nil {
+		t.Fatalf("ListVMs() returned an error: %s", err)
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"params":"command=listVirtualMachines&response=json"`) || strings.Contains(string(b), "secretapikey") {
+		t.Errorf("recording doesn't contain the redacted call:\n%s", b)
+	}
+
+	// The recorded response is returned without calling the API.
+	rep, err := NewRegistry().openReplayer(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientMap = map[string]*Client{"nl1": newClient("nl1", "replay:nl1", "", "", clientOptions{replay: rep})}
+	for i := 0; i < 2; i++ {
+		vms, err := ListVMs(context.Background(), clientMap)
+		if err != nil {
+			t.Fatalf("ListVMs() returned an error: %s", err)
+		}
+		if len(vms) != 1 || vms[0].Name != "vm1" {
+			t.Errorf("ListVMs() = %+v, want the recorded vm1", vms)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("API received %d requests, want 1", n)
+	}
+
+	if _, err := ListVPCs(context.Background(), clientMap); err == nil || !strings.Contains(err.Error(), "No recorded response for command listVPCs") {
+		t.Errorf("ListVPCs() error = %v, want an error for a call that wasn't recorded", err)
+	}
+}
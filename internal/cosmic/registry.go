@@ -0,0 +1,46 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"os"
+	"sync"
+)
+
+// Registry holds the state shared by the clients of multiple NewAsyncClients calls: the rate
+// limiters of API endpoints, the open log files and the recordings. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.Mutex
+	limiters  map[string]*rateLimiter
+	logFiles  map[string]*os.File
+	recorders map[string]*recorder
+	replayers map[string]*replayer
+}
+
+// defaultRegistry is used by the NewAsyncClients function, so all clients of a cosmic-cli run
+// share the same state.
+var defaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		limiters:  map[string]*rateLimiter{},
+		logFiles:  map[string]*os.File{},
+		recorders: map[string]*recorder{},
+		replayers: map[string]*replayer{},
+	}
+}
@@ -0,0 +1,137 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures how failed API calls are retried.
+type RetryPolicy struct {
+	Attempts int           // Maximum number of attempts, including the first one.
+	Backoff  time.Duration // Delay before the first retry, doubled for every next retry.
+	Jitter   float64       // Fraction by which the delay is randomly increased or decreased.
+}
+
+// maxBackoff limits the delay between two attempts.
+const maxBackoff = 30 * time.Second
+
+// delay returns the time to wait before the given retry, starting at 1.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < retry && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+
+	if p.Jitter > 0 {
+		d += time.Duration(float64(d) * p.Jitter * (2*rand.Float64() - 1))
+	}
+
+	return d
+}
+
+// retryTransport is a http.RoundTripper that retries failed requests according to its policy.
+//
+// Only API commands that don't change anything (list, get and query commands) are retried when
+// the request may have reached the API, i.e. on connection errors and on 429, 500, 502, 503 and
+// 504 responses. Other commands are only retried when the connection to the API could not be
+// made, so a create or delete call is never sent twice.
+type retryTransport struct {
+	next   http.RoundTripper
+	policy RetryPolicy
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	safe := isSafeCommand(req.URL.Query().Get("command"))
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.policy.Attempts || req.Context().Err() != nil || !shouldRetry(safe, resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), t.policy.delay(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isSafeCommand returns true if the API command doesn't change anything and may be repeated.
+func isSafeCommand(command string) bool {
+	for _, prefix := range []string{"list", "get", "query"} {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldRetry returns true if the result of a request should be retried.
+func shouldRetry(safe bool, resp *http.Response, err error) bool {
+	if err != nil {
+		if oe, ok := err.(*net.OpError); ok && oe.Op == "dial" {
+			return true
+		}
+		return safe
+	}
+
+	if !safe {
+		return false
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,83 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer returns a test server that responds with a 503 to the first failures requests.
+// The number of requests received is counted in requests.
+func newFlakyServer(failures int32, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(requests, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"errorresponse":{"errorcode":503,"errortext":"service unavailable"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"listvirtualmachinesresponse":{"count":1,"virtualmachine":[{"id":"1","name":"vm1"}]}}`)
+	}))
+}
+
+func TestRetrySafeCommand(t *testing.T) {
+	var requests int32
+	ts := newFlakyServer(2, &requests)
+	defer ts.Close()
+
+	opts := clientOptions{retry: RetryPolicy{Attempts: 3, Backoff: time.Millisecond, Jitter: 0.5}}
+	clientMap := map[string]*Client{"nl1": newClient("nl1", ts.URL, "key", "secret", opts)}
+
+	vms, err := ListVMs(context.Background(), clientMap)
+	if err != nil {
+		t.Fatalf("ListVMs() returned an error: %s", err)
+	}
+	if len(vms) != 1 || requests != 3 {
+		t.Errorf("ListVMs() returned %d instances using %d requests, want 1 instance using 3 requests", len(vms), requests)
+	}
+}
+
+func TestRetryUnsafeCommand(t *testing.T) {
+	var requests int32
+	ts := newFlakyServer(1, &requests)
+	defer ts.Close()
+
+	opts := clientOptions{retry: RetryPolicy{Attempts: 3, Backoff: time.Millisecond}}
+	clientMap := map[string]*Client{"nl1": newClient("nl1", ts.URL, "key", "secret", opts)}
+
+	if err := CreateVPCRoute(context.Background(), clientMap, "vpc", "10.0.0.1", "10.1.0.0/16"); err == nil {
+		t.Errorf("CreateVPCRoute() did not return an error")
+	}
+	if requests != 1 {
+		t.Errorf("CreateVPCRoute() sent %d requests, want 1", requests)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{Backoff: time.Second}
+
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: maxBackoff} {
+		if got := p.delay(retry); got != want {
+			t.Errorf("delay(%d) = %s, want %s", retry, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
+)
+
+// Services contains the API services used by cosmic-cli. They are implemented by the services of
+// a *cosmic.CosmicClient, and by the in-memory API in internal/cosmic/fake.
+type Services struct {
+	CloudOps        CloudOpsService
+	Network         NetworkService
+	NetworkACL      NetworkACLService
+	PublicIPAddress PublicIPAddressService
+	VirtualMachine  VirtualMachineService
+	VPC             VPCService
+	Zone            ZoneService
+}
+
+// CloudOpsService is the part of *cosmic.CloudOpsService used by cosmic-cli.
+type CloudOpsService interface {
+	NewListWhoHasThisIpParams(ipaddress string) *cosmic.ListWhoHasThisIpParams
+	ListWhoHasThisIp(p *cosmic.ListWhoHasThisIpParams) (*cosmic.ListWhoHasThisIpResponse, error)
+	NewListWhoHasThisMacParams() *cosmic.ListWhoHasThisMacParams
+	ListWhoHasThisMac(p *cosmic.ListWhoHasThisMacParams) (*cosmic.ListWhoHasThisMacResponse, error)
+}
+
+// NetworkService is the part of *cosmic.NetworkService used by cosmic-cli.
+type NetworkService interface {
+	NewListNetworksParams() *cosmic.ListNetworksParams
+	ListNetworks(p *cosmic.ListNetworksParams) (*cosmic.ListNetworksResponse, error)
+}
+
+// NetworkACLService is the part of *cosmic.NetworkACLService used by cosmic-cli.
+type NetworkACLService interface {
+	GetNetworkACLListByID(id string, opts ...cosmic.OptionFunc) (*cosmic.NetworkACLList, int, error)
+	NewListNetworkACLListsParams() *cosmic.ListNetworkACLListsParams
+	ListNetworkACLLists(p *cosmic.ListNetworkACLListsParams) (*cosmic.ListNetworkACLListsResponse, error)
+	NewListNetworkACLsParams() *cosmic.ListNetworkACLsParams
+	ListNetworkACLs(p *cosmic.ListNetworkACLsParams) (*cosmic.ListNetworkACLsResponse, error)
+}
+
+// PublicIPAddressService is the part of *cosmic.PublicIPAddressService used by cosmic-cli.
+type PublicIPAddressService interface {
+	NewListPublicIpAddressesParams() *cosmic.ListPublicIpAddressesParams
+	ListPublicIpAddresses(p *cosmic.ListPublicIpAddressesParams) (*cosmic.ListPublicIpAddressesResponse, error)
+}
+
+// VirtualMachineService is the part of *cosmic.VirtualMachineService used by cosmic-cli.
+type VirtualMachineService interface {
+	NewListVirtualMachinesParams() *cosmic.ListVirtualMachinesParams
+	ListVirtualMachines(p *cosmic.ListVirtualMachinesParams) (*cosmic.ListVirtualMachinesResponse, error)
+}
+
+// VPCService is the part of *cosmic.VPCService used by cosmic-cli.
+type VPCService interface {
+	GetVPCByID(id string, opts ...cosmic.OptionFunc) (*cosmic.VPC, int, error)
+	GetVPCByName(name string, opts ...cosmic.OptionFunc) (*cosmic.VPC, int, error)
+	NewListVPCsParams() *cosmic.ListVPCsParams
+	ListVPCs(p *cosmic.ListVPCsParams) (*cosmic.ListVPCsResponse, error)
+	NewListPrivateGatewaysParams() *cosmic.ListPrivateGatewaysParams
+	ListPrivateGateways(p *cosmic.ListPrivateGatewaysParams) (*cosmic.ListPrivateGatewaysResponse, error)
+	NewListStaticRoutesParams() *cosmic.ListStaticRoutesParams
+	ListStaticRoutes(p *cosmic.ListStaticRoutesParams) (*cosmic.ListStaticRoutesResponse, error)
+	NewCreateStaticRouteParams(cidr string, nexthop string, vpcid string) *cosmic.CreateStaticRouteParams
+	CreateStaticRoute(p *cosmic.CreateStaticRouteParams) (*cosmic.CreateStaticRouteResponse, error)
+	NewDeleteStaticRouteParams(id string) *cosmic.DeleteStaticRouteParams
+	DeleteStaticRoute(p *cosmic.DeleteStaticRouteParams) (*cosmic.DeleteStaticRouteResponse, error)
+}
+
+// ZoneService is the part of *cosmic.ZoneService used by cosmic-cli.
+type ZoneService interface {
+	NewListZonesParams() *cosmic.ListZonesParams
+	ListZones(p *cosmic.ListZonesParams) (*cosmic.ListZonesResponse, error)
+}
+
+// newServices returns the services of cs.
+func newServices(cs *cosmic.CosmicClient) Services {
+	return Services{
+		CloudOps:        cs.CloudOps,
+		Network:         cs.Network,
+		NetworkACL:      cs.NetworkACL,
+		PublicIPAddress: cs.PublicIPAddress,
+		VirtualMachine:  cs.VirtualMachine,
+		VPC:             cs.VPC,
+		Zone:            cs.Zone,
+	}
+}
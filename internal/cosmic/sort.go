@@ -0,0 +1,202 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	h "github.com/shoekstra/cosmic-cli/internal/helper"
+)
+
+// InvalidSortFieldError is returned when sorting by a field that doesn't exist, or by no field.
+type InvalidSortFieldError struct {
+	Field  string // The field that doesn't exist, empty if no field was given.
+	SortBy string
+}
+
+// Error returns the invalid sort field error message.
+func (e *InvalidSortFieldError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("Invalid sort option \"%s\", provide one or more fields to sort by", e.SortBy)
+	}
+
+	return fmt.Sprintf("Invalid sort field \"%s\", no such field exists", e.Field)
+}
+
+// sortSlice sorts slice in place by one or more comma separated fields, e.g. "zonename,name". Any
+// field path accepted by helper.FieldValues can be used; when a field has multiple values the
+// first value is used. Values are compared as IP addresses/CIDRs or numbers when possible and
+// using natural ordering otherwise.
+func sortSlice(slice interface{}, sortBy string, reverseSort bool) error {
+	v := reflect.ValueOf(slice)
+	t := v.Type().Elem()
+
+	fields := []string{}
+	for _, f := range strings.Split(sortBy, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !h.HasFieldPath(t, f) {
+			return &InvalidSortFieldError{Field: f, SortBy: sortBy}
+		}
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return &InvalidSortFieldError{SortBy: sortBy}
+	}
+
+	// Look up the values to sort by once, rather than on every comparison.
+	n := v.Len()
+	keys := make([][]string, n)
+	index := make([]int, n)
+	for i := 0; i < n; i++ {
+		index[i] = i
+		for _, f := range fields {
+			key := ""
+			if values, _ := h.FieldValues(v.Index(i).Interface(), f); len(values) > 0 {
+				key = fmt.Sprintf("%v", values[0])
+			}
+			keys[i] = append(keys[i], key)
+		}
+	}
+
+	sort.SliceStable(index, func(i, j int) bool {
+		for k := range fields {
+			c := compareValues(keys[index[i]][k], keys[index[j]][k])
+			if c == 0 {
+				continue
+			}
+			if reverseSort {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	sorted := reflect.MakeSlice(v.Type(), n, n)
+	for i, x := range index {
+		sorted.Index(i).Set(v.Index(x))
+	}
+	reflect.Copy(v, sorted)
+
+	return nil
+}
+
+// compareValues compares a and b and returns -1, 0 or 1 if a is less than, equal to or greater
+// than b.
+func compareValues(a, b string) int {
+	if ipA, lenA, ok := parseAddress(a); ok {
+		if ipB, lenB, ok := parseAddress(b); ok {
+			if c := bytes.Compare(ipA, ipB); c != 0 {
+				return c
+			}
+			return compareInts(lenA, lenB)
+		}
+	}
+
+	if numA, err := strconv.ParseFloat(a, 64); err == nil {
+		if numB, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case numA < numB:
+				return -1
+			case numA > numB:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	return compareNatural(a, b)
+}
+
+// compareNatural compares strings case-insensitively, treating runs of digits as numbers so that
+// "vm2" sorts before "vm10".
+func compareNatural(a, b string) int {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+
+	for a != "" && b != "" {
+		chunkA, restA := nextChunk(a)
+		chunkB, restB := nextChunk(b)
+
+		if isDigits(chunkA) && isDigits(chunkB) {
+			numA := strings.TrimLeft(chunkA, "0")
+			numB := strings.TrimLeft(chunkB, "0")
+			if c := compareInts(len(numA), len(numB)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(numA, numB); c != 0 {
+				return c
+			}
+		} else if c := strings.Compare(chunkA, chunkB); c != 0 {
+			return c
+		}
+
+		a, b = restA, restB
+	}
+
+	return compareInts(len(a), len(b))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func isDigits(s string) bool {
+	return s != "" && unicode.IsDigit(rune(s[0]))
+}
+
+// nextChunk splits s into its leading run of either digits or non-digits and the remainder.
+func nextChunk(s string) (string, string) {
+	digits := unicode.IsDigit(rune(s[0]))
+	for i, r := range s {
+		if unicode.IsDigit(r) != digits {
+			return s[:i], s[i:]
+		}
+	}
+
+	return s, ""
+}
+
+// parseAddress parses an IP address or CIDR and returns the IP in 16-byte form and the prefix
+// length, which is -1 for plain IP addresses.
+func parseAddress(s string) (net.IP, int, bool) {
+	if ip, n, err := net.ParseCIDR(s); err == nil {
+		ones, _ := n.Mask.Size()
+		return ip.To16(), ones, true
+	}
+	if ip := net.ParseIP(s); ip != nil {
+		return ip.To16(), -1, true
+	}
+
+	return nil, 0, false
+}
@@ -0,0 +1,77 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"testing"
+
+	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
+)
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"10.0.0.2", "10.0.0.10", -1},
+		{"10.0.0.0/8", "10.0.0.0/16", -1},
+		{"10.1.0.0/16", "9.0.0.0/8", 1},
+		{"2", "10", -1},
+		{"vm2", "vm10", -1},
+		{"VM-a", "vm-b", -1},
+		{"web01", "web1", 0},
+		{"abc", "abc", 0},
+		{"", "a", -1},
+	}
+
+	for _, tt := range tests {
+		if got := compareValues(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareValues(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSortMultipleFields(t *testing.T) {
+	srs := StaticRoutes{
+		&StaticRoute{StaticRoute: &cosmic.StaticRoute{Cidr: "10.0.0.0/24", Nexthop: "10.1.0.10"}, Vpcname: "b"},
+		&StaticRoute{StaticRoute: &cosmic.StaticRoute{Cidr: "10.0.1.0/24", Nexthop: "10.1.0.2"}, Vpcname: "a"},
+		&StaticRoute{StaticRoute: &cosmic.StaticRoute{Cidr: "10.0.2.0/24", Nexthop: "10.1.0.10"}, Vpcname: "a"},
+	}
+
+	if err := srs.Sort("vpcname, nexthop", false); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.0.0/24"}
+	for i, sr := range srs {
+		if sr.Cidr != want[i] {
+			t.Errorf("sorted route %d has cidr %s, want %s", i, sr.Cidr, want[i])
+		}
+	}
+
+	if err := srs.Sort("nexthop", true); err != nil {
+		t.Fatal(err)
+	}
+	if srs[2].Nexthop != "10.1.0.2" {
+		t.Errorf("reverse sorted routes end with nexthop %s, want 10.1.0.2", srs[2].Nexthop)
+	}
+}
+
+func TestSortInvalidField(t *testing.T) {
+	if err := (VirtualMachines{}).Sort("name,nonexistent", false); err == nil {
+		t.Error("sorting by a nonexistent field did not return an error")
+	}
+}
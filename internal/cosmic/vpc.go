@@ -17,20 +17,18 @@
 package cosmic
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"sort"
 	"strings"
-	"sync"
 
 	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
-	h "github.com/shoekstra/cosmic-cli/internal/helper"
 )
 
 // VPC embeds *cosmic.VPC to allow additional fields.
 type VPC struct {
 	*cosmic.VPC
-	Sourcenatip string
+	ProfileMeta
+	Sourcenatip string `json:"sourcenatip,omitempty"`
 }
 
 // VPCs exists to provide helper methods for []*VPC.
@@ -67,47 +65,13 @@ func (v VPCs) FindByName(name string) ([]*VPC, error) {
 	return r, nil
 }
 
-// Sort will sort VPCs by either the "cidr", "name", "vpcofferingname" or "zonename" field.
-func (v VPCs) Sort(sortBy string, reverseSort bool) {
-	if !h.Contains([]string{"cidr", "name", "vpcofferingname", "zonename"}, sortBy) {
-		fmt.Println("Invalid sort option provided, provide either \"cidr\", \"name\", \"vpcofferingname\" or \"zonename\".")
-		os.Exit(1)
-	}
-
-	switch {
-	case strings.EqualFold(sortBy, "Cidr"):
-		sort.SliceStable(v, func(i, j int) bool {
-			if reverseSort {
-				return v[i].Cidr > v[j].Cidr
-			}
-			return v[i].Cidr < v[j].Cidr
-		})
-	case strings.EqualFold(sortBy, "Name"):
-		sort.SliceStable(v, func(i, j int) bool {
-			if reverseSort {
-				return v[i].Name > v[j].Name
-			}
-			return v[i].Name < v[j].Name
-		})
-	case strings.EqualFold(sortBy, "Vpcofferingname"):
-		sort.SliceStable(v, func(i, j int) bool {
-			if reverseSort {
-				return v[i].Vpcofferingname > v[j].Vpcofferingname
-			}
-			return v[i].Vpcofferingname < v[j].Vpcofferingname
-		})
-	case strings.EqualFold(sortBy, "Zonename"):
-		sort.SliceStable(v, func(i, j int) bool {
-			if reverseSort {
-				return v[i].Zonename > v[j].Zonename
-			}
-			return v[i].Zonename < v[j].Zonename
-		})
-	}
+// Sort will sort VPCs by one or more comma separated fields, e.g. "zonename,name".
+func (v VPCs) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(v, sortBy, reverseSort)
 }
 
-// VPCGetByID returns a *cosmic.VPC object using a *cosmic.CosmicClient object.
-func VPCGetByID(client *cosmic.CosmicClient, id string) (*cosmic.VPC, int, error) {
+// VPCGetByID returns a *cosmic.VPC object using a *Client object.
+func VPCGetByID(client *Client, id string) (*cosmic.VPC, int, error) {
 	resp, count, err := client.VPC.GetVPCByID(id)
 
 	if err != nil {
@@ -120,8 +84,8 @@ func VPCGetByID(client *cosmic.CosmicClient, id string) (*cosmic.VPC, int, error
 	return resp, count, nil
 }
 
-// VPCGetByName returns a *cosmic.VPC object using a *cosmic.CosmicClient object.
-func VPCGetByName(client *cosmic.CosmicClient, name string) (*cosmic.VPC, int, error) {
+// VPCGetByName returns a *cosmic.VPC object using a *Client object.
+func VPCGetByName(client *Client, name string) (*cosmic.VPC, int, error) {
 	resp, count, err := client.VPC.GetVPCByName(name)
 
 	if err != nil {
@@ -134,71 +98,54 @@ func VPCGetByName(client *cosmic.CosmicClient, name string) (*cosmic.VPC, int, e
 	return resp, count, nil
 }
 
-// ListVPCs returns a slice of *VPC objects using all configured *cosmic.CosmicClient objects.
-func ListVPCs(clientMap map[string]*cosmic.CosmicClient) (VPCs, error) {
+// ListVPCs returns a slice of *VPC objects using all configured *Client objects.
+func ListVPCs(ctx context.Context, clientMap map[string]*Client) (VPCs, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.VPC.NewListVPCsParams()
+		resp, err := client.VPC.ListVPCs(params)
+		if err != nil {
+			return nil, err
+		}
+
+		vpcs := []*VPC{}
+		for _, vpc := range resp.VPCs {
+			vpcs = append(vpcs, &VPC{
+				VPC:         vpc,
+				ProfileMeta: client.meta,
+			})
+		}
+
+		return vpcs, nil
+	})
+
 	vpcs := []*VPC{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			params := clientMap[client].VPC.NewListVPCsParams()
-			resp, err := clientMap[client].VPC.ListVPCs(params)
-			if err != nil {
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
-			}
-
-			for _, vpc := range resp.VPCs {
-				vpcs = append(vpcs, &VPC{
-					VPC: vpc,
-				})
-			}
-		}(client)
+	for _, r := range results {
+		vpcs = append(vpcs, r.([]*VPC)...)
 	}
 
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
+	return vpcs, err
+}
 
-	select {
-	case <-finished:
-	case err := <-errChannel:
-		if err != nil {
+// VPCGetAllByID returns a slice of *VPC objects using all configured *Client objects.
+func VPCGetAllByID(ctx context.Context, clientMap map[string]*Client, id string) ([]*VPC, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		vpc, count, err := VPCGetByID(client, id)
+		if err != nil || count != 1 {
 			return nil, err
 		}
-	}
 
-	return vpcs, nil
-}
+		return &VPC{VPC: vpc, ProfileMeta: client.meta}, nil
+	})
 
-// VPCGetAllByID returns a slice of *VPC objects using all configured *cosmic.CosmicClient objects.
-func VPCGetAllByID(clientMap map[string]*cosmic.CosmicClient, id string) ([]*VPC, error) {
 	vpcs := []*VPC{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			vpc, count, _ := VPCGetByID(clientMap[client], id)
-			if count == 1 {
-				vpcs = append(vpcs, &VPC{
-					VPC: vpc,
-				})
-			}
-		}(client)
+	for _, r := range results {
+		vpcs = append(vpcs, r.(*VPC))
 	}
-	wg.Wait()
 
 	if len(vpcs) == 0 {
+		if err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("No match found for VPC with id %s", id)
 	}
 
@@ -209,27 +156,26 @@ func VPCGetAllByID(clientMap map[string]*cosmic.CosmicClient, id string) ([]*VPC
 	return vpcs, nil
 }
 
-// VPCGetAllByName returns a slice of *VPC objects using all configured *cosmic.CosmicClient objects.
-func VPCGetAllByName(clientMap map[string]*cosmic.CosmicClient, name string) ([]*VPC, error) {
+// VPCGetAllByName returns a slice of *VPC objects using all configured *Client objects.
+func VPCGetAllByName(ctx context.Context, clientMap map[string]*Client, name string) ([]*VPC, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		vpc, count, err := VPCGetByName(client, name)
+		if err != nil || count != 1 {
+			return nil, err
+		}
+
+		return &VPC{VPC: vpc, ProfileMeta: client.meta}, nil
+	})
+
 	vpcs := []*VPC{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			vpc, count, _ := VPCGetByName(clientMap[client], name)
-			if count == 1 {
-				vpcs = append(vpcs, &VPC{
-					VPC: vpc,
-				})
-			}
-		}(client)
+	for _, r := range results {
+		vpcs = append(vpcs, r.(*VPC))
 	}
-	wg.Wait()
 
 	if len(vpcs) == 0 {
+		if err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("No match found for VPC with name %s", name)
 	}
 
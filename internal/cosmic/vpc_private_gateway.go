@@ -17,21 +17,19 @@
 package cosmic
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"sort"
 	"strings"
-	"sync"
 
 	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
-	h "github.com/shoekstra/cosmic-cli/internal/helper"
 )
 
 // PrivateGateway embeds *cosmic.PrivateGateway to allow additional fields.
 type PrivateGateway struct {
 	*cosmic.PrivateGateway
-	Vpccidr string
-	Vpcname string
+	ProfileMeta
+	Vpccidr string `json:"vpccidr,omitempty"`
+	Vpcname string `json:"vpcname,omitempty"`
 }
 
 // PrivateGateways exists to provide helper methods for []*PrivateGateway.
@@ -48,102 +46,50 @@ func (p PrivateGateways) FindByIPAddress(ip string) []*PrivateGateway {
 	return pgws
 }
 
-// Sort will sort PrivateGateways by either the "cidr" or "name" field.
-func (p PrivateGateways) Sort(sortBy string, reverseSort bool) {
-	if !h.Contains([]string{"cidr", "ipaddress", "vpccidr", "vpcname", "zonename"}, sortBy) {
-		fmt.Println("Invalid sort option provided, provide either \"cidr\", \"ipaddress\", \"vpccidr\", \"vpcname\" or \"zonename\".")
-		os.Exit(1)
-	}
-	switch {
-	case strings.EqualFold(sortBy, "Cidr"):
-		sort.SliceStable(p, func(i, j int) bool {
-			if reverseSort {
-				return p[i].Cidr > p[j].Cidr
-			}
-			return p[i].Cidr < p[j].Cidr
-		})
-	case strings.EqualFold(sortBy, "Ipaddress"):
-		sort.SliceStable(p, func(i, j int) bool {
-			if reverseSort {
-				return p[i].Ipaddress > p[j].Ipaddress
-			}
-			return p[i].Ipaddress < p[j].Ipaddress
-		})
-	case strings.EqualFold(sortBy, "Vpccidr"):
-		sort.SliceStable(p, func(i, j int) bool {
-			if reverseSort {
-				return p[i].Vpccidr > p[j].Vpccidr
-			}
-			return p[i].Vpccidr < p[j].Vpccidr
-		})
-	case strings.EqualFold(sortBy, "Vpcname"):
-		sort.SliceStable(p, func(i, j int) bool {
-			if reverseSort {
-				return p[i].Vpcname > p[j].Vpcname
-			}
-			return p[i].Vpcname < p[j].Vpcname
-		})
-	case strings.EqualFold(sortBy, "Zonename"):
-		sort.SliceStable(p, func(i, j int) bool {
-			if reverseSort {
-				return p[i].Zonename > p[j].Zonename
-			}
-			return p[i].Zonename < p[j].Zonename
-		})
-	}
+// Sort will sort PrivateGateways by one or more comma separated fields, e.g. "zonename,name".
+func (p PrivateGateways) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(p, sortBy, reverseSort)
 }
 
-// ListVPCPrivateGateways returns a PrivateGateways object using all configured *cosmic.CosmicClient objects.
-func ListVPCPrivateGateways(clientMap map[string]*cosmic.CosmicClient) (PrivateGateways, error) {
-	pgws := []*PrivateGateway{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
+// ListVPCPrivateGateways returns a PrivateGateways object using all configured *Client objects.
+func ListVPCPrivateGateways(ctx context.Context, clientMap map[string]*Client) (PrivateGateways, error) {
+	errs := &ProfileErrors{Profiles: len(clientMap)}
 
-	VPCs, err := ListVPCs(clientMap)
-	if err != nil {
+	VPCs, err := ListVPCs(ctx, clientMap)
+	if err = errs.Collect(err); err != nil {
 		return nil, err
 	}
 
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			params := clientMap[client].VPC.NewListPrivateGatewaysParams()
-			resp, err := clientMap[client].VPC.ListPrivateGateways(params)
-			if err != nil {
-				if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
-					return
-				}
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.VPC.NewListPrivateGatewaysParams()
+		resp, err := client.VPC.ListPrivateGateways(params)
+		if err != nil {
+			if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
+				return nil, nil
 			}
+			return nil, err
+		}
 
-			for _, pgw := range resp.PrivateGateways {
-				v, _ := VPCs.FindByID(pgw.Vpcid)
-				pgws = append(pgws, &PrivateGateway{
-					PrivateGateway: pgw,
-					Vpccidr:        v[0].Cidr,
-					Vpcname:        v[0].Name,
-				})
+		pgws := []*PrivateGateway{}
+		for _, pgw := range resp.PrivateGateways {
+			p := &PrivateGateway{PrivateGateway: pgw, ProfileMeta: client.meta}
+			if v, err := VPCs.FindByID(pgw.Vpcid); err == nil {
+				p.Vpccidr = v[0].Cidr
+				p.Vpcname = v[0].Name
 			}
-		}(client)
-	}
+			pgws = append(pgws, p)
+		}
 
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
+		return pgws, nil
+	})
+	if err = errs.Collect(err); err != nil {
+		return nil, err
+	}
 
-	select {
-	case <-finished:
-	case err := <-errChannel:
-		if err != nil {
-			return nil, err
-		}
+	pgws := []*PrivateGateway{}
+	for _, r := range results {
+		pgws = append(pgws, r.([]*PrivateGateway)...)
 	}
 
-	return pgws, nil
+	return pgws, errs.ErrorOrNil()
 }
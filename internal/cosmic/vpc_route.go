@@ -17,172 +17,92 @@
 package cosmic
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"sort"
 	"strings"
-	"sync"
 
 	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
-	h "github.com/shoekstra/cosmic-cli/internal/helper"
 )
 
 // StaticRoute embeds *cosmic.StaticRoute to allow additional fields.
 type StaticRoute struct {
 	*cosmic.StaticRoute
-	Vpcname string
+	ProfileMeta
+	Vpcname string `json:"vpcname,omitempty"`
 }
 
 // StaticRoutes exists to provide helper methods for []*StaticRoute.
 type StaticRoutes []*StaticRoute
 
-// Sort will sort StaticRoutes by either the "cidr" or "nexthop" field.
-func (srs StaticRoutes) Sort(sortBy string, reverseSort bool) {
-	if !h.Contains([]string{"cidr", "nexthop"}, sortBy) {
-		fmt.Println("Invalid sort option provided, provide either \"cidr\" or \"nexthop\".")
-		os.Exit(1)
-	}
-
-	switch {
-	case strings.EqualFold(sortBy, "Cidr"):
-		sort.SliceStable(srs, func(i, j int) bool {
-			if reverseSort {
-				return srs[i].Cidr > srs[j].Cidr
-			}
-			return srs[i].Cidr < srs[j].Cidr
-		})
-	case strings.EqualFold(sortBy, "Nexthop"):
-		sort.SliceStable(srs, func(i, j int) bool {
-			if reverseSort {
-				return srs[i].Nexthop > srs[j].Nexthop
-			}
-			return srs[i].Nexthop < srs[j].Nexthop
-		})
-	}
+// Sort will sort StaticRoutes by one or more comma separated fields, e.g. "zonename,name".
+func (srs StaticRoutes) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(srs, sortBy, reverseSort)
 }
 
-// CreateVPCRoute loops through all configured *cosmic.CosmicClient objects and adds a new
+// CreateVPCRoute loops through all configured *Client objects and adds a new
 // VPC static route if the provided VPC ID is found.
-func CreateVPCRoute(clientMap map[string]*cosmic.CosmicClient, vpcID, nextHop string, cidr string) error {
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			params := clientMap[client].VPC.NewCreateStaticRouteParams(cidr, nextHop, vpcID)
-			if _, err := clientMap[client].VPC.CreateStaticRoute(params); err != nil {
-				if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
-					return
-				}
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
+func CreateVPCRoute(ctx context.Context, clientMap map[string]*Client, vpcID, nextHop string, cidr string) error {
+	_, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.VPC.NewCreateStaticRouteParams(cidr, nextHop, vpcID)
+		if _, err := client.VPC.CreateStaticRoute(params); err != nil {
+			if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
+				return nil, nil
 			}
-		}(client)
-	}
-
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
-
-	select {
-	case <-finished:
-	case err := <-errChannel:
-		if err != nil {
-			return err
+			return nil, err
 		}
-	}
 
-	return nil
+		return nil, nil
+	})
+
+	return err
 }
 
-// DeleteVPCRoute loops through all configured *cosmic.CosmicClient objects and removes an
+// DeleteVPCRoute loops through all configured *Client objects and removes an
 // existing VPC static route if the provided VPC ID is found.
-func DeleteVPCRoute(clientMap map[string]*cosmic.CosmicClient, id string) error {
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			params := clientMap[client].VPC.NewDeleteStaticRouteParams(id)
-			if _, err := clientMap[client].VPC.DeleteStaticRoute(params); err != nil {
-				if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
-					return
-				}
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
+func DeleteVPCRoute(ctx context.Context, clientMap map[string]*Client, id string) error {
+	_, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.VPC.NewDeleteStaticRouteParams(id)
+		if _, err := client.VPC.DeleteStaticRoute(params); err != nil {
+			if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
+				return nil, nil
 			}
-		}(client)
-	}
-
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
-
-	select {
-	case <-finished:
-	case err := <-errChannel:
-		if err != nil {
-			return err
+			return nil, err
 		}
-	}
 
-	return nil
+		return nil, nil
+	})
+
+	return err
 }
 
-// ListVPCRoutes returns a StaticRoutes object using all configured *cosmic.CosmicClient objects.
-func ListVPCRoutes(clientMap map[string]*cosmic.CosmicClient, vpcID string) (StaticRoutes, error) {
-	srs := []*StaticRoute{}
-	wg := sync.WaitGroup{}
-	wg.Add(len(clientMap))
-
-	errChannel := make(chan error, 1)
-	finished := make(chan bool, 1)
-
-	for client := range clientMap {
-		go func(client string) {
-			defer wg.Done()
-
-			params := clientMap[client].VPC.NewListStaticRoutesParams()
-			params.SetVpcid(vpcID)
-			resp, err := clientMap[client].VPC.ListStaticRoutes(params)
-			if err != nil {
-				if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
-					return
-				}
-				errChannel <- profileError{fmt.Sprintf("Error returned using profile \"%s\": %s", client, err)}
-				return
+// ListVPCRoutes returns a StaticRoutes object using all configured *Client objects.
+func ListVPCRoutes(ctx context.Context, clientMap map[string]*Client, vpcID string) (StaticRoutes, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.VPC.NewListStaticRoutesParams()
+		params.SetVpcid(vpcID)
+		resp, err := client.VPC.ListStaticRoutes(params)
+		if err != nil {
+			if strings.Contains(err.Error(), fmt.Sprintf("entity does not exist")) {
+				return nil, nil
 			}
+			return nil, err
+		}
 
-			for _, sr := range resp.StaticRoutes {
-				srs = append(srs, &StaticRoute{
-					StaticRoute: sr,
-				})
-			}
-		}(client)
-	}
+		srs := []*StaticRoute{}
+		for _, sr := range resp.StaticRoutes {
+			srs = append(srs, &StaticRoute{
+				StaticRoute: sr,
+				ProfileMeta: client.meta,
+			})
+		}
 
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
+		return srs, nil
+	})
 
-	select {
-	case <-finished:
-	case err := <-errChannel:
-		if err != nil {
-			return nil, err
-		}
+	srs := []*StaticRoute{}
+	for _, r := range results {
+		srs = append(srs, r.([]*StaticRoute)...)
 	}
 
-	return srs, nil
+	return srs, err
 }
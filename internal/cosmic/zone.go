@@ -0,0 +1,65 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmic
+
+import (
+	"context"
+
+	"github.com/MissionCriticalCloud/go-cosmic/cosmic"
+)
+
+// Zone embeds *cosmic.Zone to allow additional fields.
+type Zone struct {
+	*cosmic.Zone
+	ProfileMeta
+}
+
+// Zones exists to provide helper methods for []*Zone.
+type Zones []*Zone
+
+// Sort will sort Zones by one or more comma separated fields, e.g. "profile,name".
+func (z Zones) Sort(sortBy string, reverseSort bool) error {
+	return sortSlice(z, sortBy, reverseSort)
+}
+
+// ListZones returns a Zones object using all configured *Client objects.
+func ListZones(ctx context.Context, clientMap map[string]*Client) (Zones, error) {
+	results, err := fanOut(ctx, clientMap, func(profile string, client *Client) (interface{}, error) {
+		params := client.Zone.NewListZonesParams()
+		resp, err := client.Zone.ListZones(params)
+		if err != nil {
+			return nil, err
+		}
+
+		zones := []*Zone{}
+		for _, z := range resp.Zones {
+			zones = append(zones, &Zone{
+				Zone:        z,
+				ProfileMeta: client.meta,
+			})
+		}
+
+		return zones, nil
+	})
+
+	zones := []*Zone{}
+	for _, r := range results {
+		zones = append(zones, r.([]*Zone)...)
+	}
+
+	return zones, err
+}
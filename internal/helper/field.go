@@ -0,0 +1,263 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package helper
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldAliaser is implemented by types that provide alternative names for field paths, e.g. to
+// make "ipaddress" an alias for "nic.*.ipaddress".
+type FieldAliaser interface {
+	FieldAliases() map[string]string
+}
+
+// FieldValues returns all values found by following path from obj. A path is a "." separated
+// list of case-insensitive field names, where:
+//
+//   - a field name can be suffixed with an index to select a single slice element, e.g. "nic[0]"
+//   - a "*" selects all elements of a slice, e.g. "nic.*.ipaddress"; a field name following a
+//     slice without an index or "*" is also looked up in all elements
+//   - a field name following a slice of key/value pairs (such as tags) or a map selects the value
+//     with that key, e.g. "tags.env"
+//
+// The returned bool is false if path does not exist on obj; a path into an empty slice returns no
+// values but is considered to exist.
+func FieldValues(obj interface{}, path string) ([]reflect.Value, bool) {
+	if !HasFieldPath(reflect.TypeOf(obj), path) {
+		return nil, false
+	}
+	path = resolveAlias(reflect.TypeOf(obj), path)
+
+	values := []reflect.Value{reflect.ValueOf(obj)}
+	for _, segment := range strings.Split(path, ".") {
+		name, index, _ := splitIndex(segment)
+
+		next := []reflect.Value{}
+		for _, val := range values {
+			next = append(next, lookupSegment(val, name, index)...)
+		}
+		values = next
+	}
+
+	return values, true
+}
+
+// FormatValues formats values as a comma separated string.
+func FormatValues(values []reflect.Value) string {
+	s := []string{}
+	for _, v := range values {
+		s = append(s, fmt.Sprintf("%v", v))
+	}
+
+	return strings.Join(s, ", ")
+}
+
+// HasFieldPath returns true if path, as accepted by FieldValues, exists on type t.
+func HasFieldPath(t reflect.Type, path string) bool {
+	path = resolveAlias(t, path)
+
+	for _, segment := range strings.Split(path, ".") {
+		name, index, err := splitIndex(segment)
+		if err != nil {
+			return false
+		}
+
+		t = indirectType(t)
+		if name == "*" {
+			if t.Kind() != reflect.Slice {
+				return false
+			}
+			t = t.Elem()
+			continue
+		}
+
+		// Look up fields in slice elements.
+		for t.Kind() == reflect.Slice {
+			t = indirectType(t.Elem())
+		}
+
+		switch {
+		case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+			t = t.Elem()
+		case t.Kind() == reflect.Struct:
+			f, ok := t.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+			if ok {
+				t = f.Type
+				break
+			}
+			if !isKeyValue(t) {
+				return false
+			}
+			v, _ := t.FieldByName("Value")
+			t = v.Type
+		default:
+			return false
+		}
+
+		if index >= 0 {
+			if t.Kind() != reflect.Slice {
+				return false
+			}
+			t = t.Elem()
+		}
+	}
+
+	return true
+}
+
+// fieldByIndex returns the nested field of val by index, returning false instead of panicking
+// when an embedded pointer is nil.
+func fieldByIndex(val reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			if val.Kind() == reflect.Ptr {
+				if val.IsNil() {
+					return reflect.Value{}, false
+				}
+				val = val.Elem()
+			}
+		}
+		val = val.Field(x)
+	}
+
+	return val, true
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// isKeyValue returns true if t is a struct with both a Key and a Value field.
+func isKeyValue(t reflect.Type) bool {
+	_, key := t.FieldByName("Key")
+	_, value := t.FieldByName("Value")
+
+	return key && value
+}
+
+// lookupSegment returns the values selected by a single path segment from val.
+func lookupSegment(val reflect.Value, name string, index int) []reflect.Value {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	if name == "*" {
+		return sliceElements(val)
+	}
+
+	values := []reflect.Value{}
+	switch val.Kind() {
+	case reflect.Slice:
+		for _, e := range sliceElements(val) {
+			values = append(values, lookupSegment(e, name, index)...)
+		}
+		return values
+	case reflect.Map:
+		for _, k := range val.MapKeys() {
+			if strings.EqualFold(k.String(), name) {
+				values = append(values, val.MapIndex(k))
+			}
+		}
+	case reflect.Struct:
+		f, ok := val.Type().FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+		if !ok {
+			// Return the value of a key/value pair; key/value pairs are generally used in
+			// slices so the key is matched in lookupSegment's slice case.
+			if isKeyValue(val.Type()) && strings.EqualFold(val.FieldByName("Key").String(), name) {
+				values = append(values, val.FieldByName("Value"))
+			}
+			break
+		}
+		if v, ok := fieldByIndex(val, f.Index); ok {
+			values = append(values, v)
+		}
+	}
+
+	if index >= 0 {
+		indexed := []reflect.Value{}
+		for _, v := range values {
+			if v.Kind() == reflect.Slice && index < v.Len() {
+				indexed = append(indexed, v.Index(index))
+			}
+		}
+		values = indexed
+	}
+
+	return values
+}
+
+// resolveAlias returns the path the first segment of path is an alias for, if t implements
+// FieldAliaser.
+func resolveAlias(t reflect.Type, path string) string {
+	if t == nil || !t.Implements(reflect.TypeOf((*FieldAliaser)(nil)).Elem()) {
+		return path
+	}
+	aliases := reflect.Zero(t).Interface().(FieldAliaser).FieldAliases()
+
+	split := strings.SplitN(path, ".", 2)
+	for alias, p := range aliases {
+		if strings.EqualFold(alias, split[0]) {
+			split[0] = p
+			return strings.Join(split, ".")
+		}
+	}
+
+	return path
+}
+
+func sliceElements(val reflect.Value) []reflect.Value {
+	if val.Kind() != reflect.Slice {
+		return nil
+	}
+
+	values := []reflect.Value{}
+	for i := 0; i < val.Len(); i++ {
+		values = append(values, val.Index(i))
+	}
+
+	return values
+}
+
+// splitIndex splits a path segment such as "Nic[0]" into its name and index; index is -1 when
+// the segment has no index.
+func splitIndex(segment string) (string, int, error) {
+	i := strings.Index(segment, "[")
+	if i == -1 {
+		return segment, -1, nil
+	}
+	if !strings.HasSuffix(segment, "]") {
+		return "", 0, fmt.Errorf("Invalid index in path segment \"%s\"", segment)
+	}
+
+	index, err := strconv.Atoi(segment[i+1 : len(segment)-1])
+	if err != nil || index < 0 {
+		return "", 0, fmt.Errorf("Invalid index in path segment \"%s\"", segment)
+	}
+
+	return segment[:i], index, nil
+}
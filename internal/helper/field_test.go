@@ -0,0 +1,110 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testNic struct {
+	Ipaddress string
+}
+
+type testTag struct {
+	Key   string
+	Value string
+}
+
+type testObject struct {
+	Name string
+	Nic  []testNic
+	Tags []testTag
+}
+
+type testWrapper struct {
+	*testObject
+	Zonename string
+}
+
+func (w *testWrapper) FieldAliases() map[string]string {
+	return map[string]string{"ipaddress": "nic.*.ipaddress"}
+}
+
+func TestFieldValues(t *testing.T) {
+	obj := &testWrapper{
+		testObject: &testObject{
+			Name: "vm1",
+			Nic:  []testNic{{Ipaddress: "10.0.0.1"}, {Ipaddress: "10.1.0.1"}},
+			Tags: []testTag{{Key: "env", Value: "prod"}, {Key: "team", Value: "ops"}},
+		},
+		Zonename: "zone1",
+	}
+
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"name", "vm1", true},
+		{"ZONENAME", "zone1", true},
+		{"nic[0].ipaddress", "10.0.0.1", true},
+		{"nic[1].ipaddress", "10.1.0.1", true},
+		{"nic[2].ipaddress", "", true},
+		{"nic.*.ipaddress", "10.0.0.1, 10.1.0.1", true},
+		{"nic.ipaddress", "10.0.0.1, 10.1.0.1", true},
+		{"ipaddress", "10.0.0.1, 10.1.0.1", true},
+		{"tags.env", "prod", true},
+		{"tags.owner", "", true},
+		{"nonexistent", "", false},
+		{"nic.*.nonexistent", "", false},
+	}
+
+	for _, tt := range tests {
+		values, ok := FieldValues(obj, tt.path)
+		if ok != tt.ok {
+			t.Errorf("FieldValues(%q): got ok %v, want %v", tt.path, ok, tt.ok)
+			continue
+		}
+		if got := FormatValues(values); got != tt.want {
+			t.Errorf("FieldValues(%q): got %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFieldValuesNoNic(t *testing.T) {
+	obj := &testWrapper{testObject: &testObject{Name: "vm1"}}
+
+	values, ok := FieldValues(obj, "ipaddress")
+	if !ok || len(values) != 0 {
+		t.Errorf("FieldValues on instance without NIC: got %v, %v", values, ok)
+	}
+}
+
+func TestHasFieldPath(t *testing.T) {
+	typ := reflect.TypeOf(&testWrapper{})
+	for _, path := range []string{"name", "zonename", "ipaddress", "nic[0].ipaddress", "nic.*.ipaddress", "tags.env"} {
+		if !HasFieldPath(typ, path) {
+			t.Errorf("HasFieldPath(%q) returned false", path)
+		}
+	}
+	for _, path := range []string{"nonexistent", "name.*", "nic[x]", "zonename[0]"} {
+		if HasFieldPath(typ, path) {
+			t.Errorf("HasFieldPath(%q) returned true", path)
+		}
+	}
+}
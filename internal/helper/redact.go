@@ -0,0 +1,38 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package helper
+
+import (
+	"regexp"
+)
+
+var (
+	// reQueryCredential matches credentials passed as URL query or form parameters; as values are
+	// URL encoded, a value ends at the first character that can't be part of it.
+	reQueryCredential = regexp.MustCompile(`(?i)\b(apikey|secretkey|secret_key|api_key|signature)=[^&\s":]+`)
+
+	// reJSONCredential matches credentials returned in JSON responses, e.g. by listUsers.
+	reJSONCredential = regexp.MustCompile(`(?i)"(apikey|secretkey|secret_key|api_key)"\s*:\s*"[^"]*"`)
+)
+
+// Redact returns s with any API keys, secret keys and signatures replaced by "**redacted**".
+func Redact(s string) string {
+	s = reQueryCredential.ReplaceAllString(s, "$1=**redacted**")
+	s = reJSONCredential.ReplaceAllString(s, `"$1":"**redacted**"`)
+
+	return s
+}
@@ -0,0 +1,48 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package helper
+
+import (
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			"Get https://api.cosmic.local/client/api/?apiKey=jDCMCLD8GGe-ThsU_KrnV&command=listVirtualMachines&signature=nx963U5Qv08W%2B02m4%3D: no such host",
+			"Get https://api.cosmic.local/client/api/?apiKey=**redacted**&command=listVirtualMachines&signature=**redacted**: no such host",
+		},
+		{
+			`Get "https://api.cosmic.local/?command=listVPCs&apikey=abc+/=def&signature=x/y+z="`,
+			`Get "https://api.cosmic.local/?command=listVPCs&apikey=**redacted**&signature=**redacted**"`,
+		},
+		{
+			`{"user":[{"username":"admin","apikey":"abc","secretkey":"def"}]}`,
+			`{"user":[{"username":"admin","apikey":"**redacted**","secretkey":"**redacted**"}]}`,
+		},
+		{"command=listZones&response=json", "command=listZones&response=json"},
+	}
+
+	for _, tt := range tests {
+		if got := Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
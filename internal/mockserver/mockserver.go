@@ -0,0 +1,324 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package mockserver implements a local HTTP server that speaks the signed Cosmic API for the API
+// calls used by cosmic-cli, using resources loaded from a YAML fixture. Async jobs are supported
+// and can be polled using queryAsyncJobResult.
+package mockserver
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic/fake"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Fixture contains the credentials and resources of the mock API. Resources use the field names
+// of the Cosmic API in lower case, e.g. "zonename" or "nic".
+type Fixture struct {
+	APIKey    string `yaml:"api_key"`
+	SecretKey string `yaml:"secret_key"`
+
+	// PendingPolls is the number of times queryAsyncJobResult reports a job as pending before it
+	// reports the result of the job. Note that go-cosmic waits up to 15 seconds between polls.
+	PendingPolls int `yaml:"pending_polls"`
+
+	ACLLists          []*gocosmic.NetworkACLList  `yaml:"acl_lists"`
+	ACLRules          []*gocosmic.NetworkACL      `yaml:"acl_rules"`
+	Networks          []*gocosmic.Network         `yaml:"networks"`
+	PrivateGateways   []*gocosmic.PrivateGateway  `yaml:"private_gateways"`
+	PublicIPAddresses []*gocosmic.PublicIpAddress `yaml:"public_ip_addresses"`
+	StaticRoutes      []*gocosmic.StaticRoute     `yaml:"static_routes"`
+	VirtualMachines   []*gocosmic.VirtualMachine  `yaml:"virtual_machines"`
+	VPCs              []*gocosmic.VPC             `yaml:"vpcs"`
+	WhoHasThisIPs     []*gocosmic.WhoHasThisIp    `yaml:"who_has_this_ip"`
+	WhoHasThisMacs    []*gocosmic.WhoHasThisMac   `yaml:"who_has_this_mac"`
+	Zones             []*gocosmic.Zone            `yaml:"zones"`
+}
+
+// LoadFixture reads a fixture from a YAML file.
+func LoadFixture(file string) (*Fixture, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Fixture{}
+	if err := yaml.UnmarshalStrict(b, f); err != nil {
+		return nil, fmt.Errorf("Error parsing fixture %s: %s", file, err)
+	}
+
+	if f.APIKey == "" || f.SecretKey == "" {
+		return nil, fmt.Errorf("Error parsing fixture %s: api_key and secret_key must be set", file)
+	}
+
+	return f, nil
+}
+
+// job is an async job started by an API call.
+type job struct {
+	command string
+	result  interface{}
+	polls   int // Number of times the job was polled.
+}
+
+// Server is an http.Handler that serves the mock API.
+type Server struct {
+	apiKey       string
+	secretKey    string
+	pendingPolls int
+	backend      *fake.Backend
+	services     cosmic.Services
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// Backend returns an in-memory API containing the resources of f.
+func (f *Fixture) Backend() *fake.Backend {
+	return &fake.Backend{
+		ACLLists:          f.ACLLists,
+		ACLRules:          f.ACLRules,
+		Networks:          f.Networks,
+		PrivateGateways:   f.PrivateGateways,
+		PublicIPAddresses: f.PublicIPAddresses,
+		StaticRoutes:      f.StaticRoutes,
+		VirtualMachines:   f.VirtualMachines,
+		VPCs:              f.VPCs,
+		WhoHasThisIPs:     f.WhoHasThisIPs,
+		WhoHasThisMacs:    f.WhoHasThisMacs,
+		Zones:             f.Zones,
+	}
+}
+
+// New returns a Server that serves the resources of f.
+func New(f *Fixture) *Server {
+	b := f.Backend()
+
+	return &Server{
+		apiKey:       f.APIKey,
+		secretKey:    f.SecretKey,
+		pendingPolls: f.PendingPolls,
+		backend:      b,
+		services:     b.Services(),
+		jobs:         make(map[string]*job),
+	}
+}
+
+// ServeHTTP handles a single API call.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	command := r.Form.Get("command")
+	key := strings.ToLower(command) + "response"
+
+	if r.Form.Get("apiKey") != s.apiKey || !s.validSignature(r.Form) {
+		writeError(w, key, &fake.APIError{
+			ErrorCode: http.StatusUnauthorized,
+			ErrorText: "unable to verify user credentials and/or request signature",
+		})
+		return
+	}
+
+	result, err := s.call(command, r.Form)
+	if err != nil {
+		apiErr, ok := err.(*fake.APIError)
+		if !ok {
+			apiErr = &fake.APIError{ErrorCode: 530, CSErrorCode: 9999, ErrorText: err.Error()}
+		}
+		writeError(w, key, apiErr)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{key: result})
+}
+
+// call runs an API command and returns its response.
+func (s *Server) call(command string, form url.Values) (interface{}, error) {
+	switch strings.ToLower(command) {
+	case "createstaticroute":
+		p := s.services.VPC.NewCreateStaticRouteParams(form.Get("cidr"), form.Get("nexthop"), form.Get("vpcid"))
+		resp, err := s.services.VPC.CreateStaticRoute(p)
+		if err != nil {
+			return nil, err
+		}
+		return s.startJob(command, resp.JobID, map[string]interface{}{"staticroute": resp}), nil
+	case "deletestaticroute":
+		resp, err := s.services.VPC.DeleteStaticRoute(s.services.VPC.NewDeleteStaticRouteParams(form.Get("id")))
+		if err != nil {
+			return nil, err
+		}
+		return s.startJob(command, resp.JobID, resp), nil
+	case "listnetworkacllists":
+		p := s.services.NetworkACL.NewListNetworkACLListsParams()
+		if id := form.Get("id"); id != "" {
+			p.SetId(id)
+		}
+		return s.services.NetworkACL.ListNetworkACLLists(p)
+	case "listnetworkacls":
+		p := s.services.NetworkACL.NewListNetworkACLsParams()
+		if aclid := form.Get("aclid"); aclid != "" {
+			p.SetAclid(aclid)
+		}
+		return s.services.NetworkACL.ListNetworkACLs(p)
+	case "listnetworks":
+		return s.services.Network.ListNetworks(s.services.Network.NewListNetworksParams())
+	case "listprivategateways":
+		return s.services.VPC.ListPrivateGateways(s.services.VPC.NewListPrivateGatewaysParams())
+	case "listpublicipaddresses":
+		return s.services.PublicIPAddress.ListPublicIpAddresses(s.services.PublicIPAddress.NewListPublicIpAddressesParams())
+	case "liststaticroutes":
+		p := s.services.VPC.NewListStaticRoutesParams()
+		if vpcid := form.Get("vpcid"); vpcid != "" {
+			p.SetVpcid(vpcid)
+		}
+		return s.services.VPC.ListStaticRoutes(p)
+	case "listvirtualmachines":
+		return s.services.VirtualMachine.ListVirtualMachines(s.services.VirtualMachine.NewListVirtualMachinesParams())
+	case "listvpcs":
+		p := s.services.VPC.NewListVPCsParams()
+		if id := form.Get("id"); id != "" {
+			p.SetId(id)
+		}
+		if name := form.Get("name"); name != "" {
+			p.SetName(name)
+		}
+		return s.services.VPC.ListVPCs(p)
+	case "listwhohasthisip":
+		return s.services.CloudOps.ListWhoHasThisIp(s.services.CloudOps.NewListWhoHasThisIpParams(form.Get("ipaddress")))
+	case "listwhohasthismac":
+		p := s.services.CloudOps.NewListWhoHasThisMacParams()
+		if mac := form.Get("macaddress"); mac != "" {
+			p.SetMacaddress(mac)
+		}
+		return s.services.CloudOps.ListWhoHasThisMac(p)
+	case "listzones":
+		return s.services.Zone.ListZones(s.services.Zone.NewListZonesParams())
+	case "queryasyncjobresult":
+		return s.queryJob(form.Get("jobid"))
+	}
+
+	return nil, &fake.APIError{
+		ErrorCode:   432,
+		CSErrorCode: 9999,
+		ErrorText:   fmt.Sprintf("The given command %s does not exist or it is not available for user", command),
+	}
+}
+
+// startJob registers the result of an async job and returns the response to the API call that
+// started it.
+func (s *Server) startJob(command, id string, result interface{}) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[id] = &job{command: command, result: result}
+
+	return map[string]string{"jobid": id}
+}
+
+// queryJob returns the status of an async job, which is pending until it was polled
+// s.pendingPolls times.
+func (s *Server) queryJob(id string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, fake.NotFoundError("queryAsyncJobResult", id)
+	}
+
+	resp := map[string]interface{}{"jobid": id, "cmd": j.command, "jobstatus": 0}
+	if j.polls < s.pendingPolls {
+		j.polls++
+		return resp, nil
+	}
+
+	resp["jobstatus"] = 1
+	resp["jobresulttype"] = "object"
+	resp["jobresult"] = j.result
+
+	return resp, nil
+}
+
+// validSignature returns true if the signature in form was created using the secret key, using
+// the same algorithm as go-cosmic.
+func (s *Server) validSignature(form url.Values) bool {
+	params := url.Values{}
+	for k, v := range form {
+		if k != "signature" {
+			params[k] = v
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(s.secretKey))
+	mac.Write([]byte(strings.Replace(strings.ToLower(encodeValues(params)), "+", "%20", -1)))
+	want := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(form.Get("signature")), []byte(want))
+}
+
+// encodeValues encodes values sorted by key, like url.Values.Encode but without escaping the keys.
+func encodeValues(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		for _, v := range values[k] {
+			if buf.Len() > 0 {
+				buf.WriteByte('&')
+			}
+			buf.WriteString(k + "=" + url.QueryEscape(v))
+		}
+	}
+
+	return buf.String()
+}
+
+func writeError(w http.ResponseWriter, key string, err *fake.APIError) {
+	writeJSON(w, err.ErrorCode, map[string]interface{}{key: err})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
@@ -0,0 +1,133 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package mockserver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gocosmic "github.com/MissionCriticalCloud/go-cosmic/cosmic"
+)
+
+func newTestServer(t *testing.T, pendingPolls int) (*httptest.Server, *Fixture) {
+	f, err := LoadFixture(filepath.Join("testdata", "fixture.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFixture() error = %v", err)
+	}
+	f.PendingPolls = pendingPolls
+
+	return httptest.NewServer(New(f)), f
+}
+
+func TestLoadFixture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmic-cli-mockserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		fixture string
+		wantErr string
+	}{
+		{"valid", "api_key: key\nsecret_key: secret\nzones:\n  - name: ams1\n", ""},
+		{"unknown field", "api_key: key\nsecret_key: secret\nzones:\n  - zone: ams1\n", "field zone not found"},
+		{"no credentials", "zones:\n  - name: ams1\n", "api_key and secret_key must be set"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, fmt.Sprintf("%d.yaml", i))
+			if err := ioutil.WriteFile(file, []byte(tt.fixture), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadFixture(file)
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("LoadFixture() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServerSignature(t *testing.T) {
+	ts, f := newTestServer(t, 0)
+	defer ts.Close()
+
+	cs := gocosmic.NewAsyncClient(ts.URL, f.APIKey, f.SecretKey, nil, 60)
+	resp, err := cs.Zone.ListZones(cs.Zone.NewListZonesParams())
+	if err != nil {
+		t.Fatalf("ListZones() error = %v", err)
+	}
+	if resp.Count != 1 || resp.Zones[0].Name != "ams1" {
+		t.Errorf("ListZones() = %+v, want zone ams1", resp)
+	}
+
+	cs = gocosmic.NewAsyncClient(ts.URL, f.APIKey, "wrong", nil, 60)
+	_, err = cs.Zone.ListZones(cs.Zone.NewListZonesParams())
+	if err == nil || !strings.Contains(err.Error(), "Cosmic API error 401") {
+		t.Errorf("ListZones() error = %v, want a 401 error for an invalid signature", err)
+	}
+}
+
+func TestServerStaticRoutes(t *testing.T) {
+	ts, f := newTestServer(t, 1)
+	defer ts.Close()
+
+	cs := gocosmic.NewAsyncClient(ts.URL, f.APIKey, f.SecretKey, nil, 60)
+	vpcid := f.VPCs[0].Id
+
+	// The job is pending for one poll before its result is returned.
+	created, err := cs.VPC.CreateStaticRoute(cs.VPC.NewCreateStaticRouteParams("10.9.0.0/16", "172.16.0.254", vpcid))
+	if err != nil {
+		t.Fatalf("CreateStaticRoute() error = %v", err)
+	}
+	if created.Id == "" || created.Cidr != "10.9.0.0/16" || created.Vpcid != vpcid {
+		t.Errorf("CreateStaticRoute() = %+v, want the created route", created)
+	}
+
+	_, err = cs.VPC.CreateStaticRoute(cs.VPC.NewCreateStaticRouteParams("10.9.0.0/16", "172.16.0.254", "unknown"))
+	if err == nil || !strings.Contains(err.Error(), "entity does not exist") {
+		t.Errorf("CreateStaticRoute() error = %v, want an error for an unknown VPC", err)
+	}
+
+	p := cs.VPC.NewListStaticRoutesParams()
+	p.SetVpcid(vpcid)
+	routes, err := cs.VPC.ListStaticRoutes(p)
+	if err != nil {
+		t.Fatalf("ListStaticRoutes() error = %v", err)
+	}
+	if routes.Count != 2 {
+		t.Errorf("ListStaticRoutes() returned %d routes, want 2", routes.Count)
+	}
+
+	deleted, err := cs.VPC.DeleteStaticRoute(cs.VPC.NewDeleteStaticRouteParams(created.Id))
+	if err != nil || !deleted.Success {
+		t.Fatalf("DeleteStaticRoute() = %+v, %v, want success", deleted, err)
+	}
+
+	routes, err = cs.VPC.ListStaticRoutes(p)
+	if err != nil || routes.Count != 1 {
+		t.Errorf("ListStaticRoutes() = %+v, %v, want 1 route", routes, err)
+	}
+}
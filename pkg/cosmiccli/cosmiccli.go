@@ -0,0 +1,145 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package cosmiccli is a client library for Cosmic Cloud that uses the profiles, config file and
+// multi-profile API calls of cosmic-cli.
+//
+// A Session runs API calls against all profiles it was created for concurrently and merges their
+// results, each resource records the profile it was returned by in its Profile field:
+//
+//	cfg, err := cosmiccli.LoadConfig("")
+//	if err != nil {
+//		return err
+//	}
+//	cfg.Parallelism = 4
+//	cfg.Timeout = time.Minute
+//
+//	s, err := cosmiccli.NewSession(cfg, "ams*,!ams3")
+//	if err != nil {
+//		return err
+//	}
+//	vms, err := s.ListVirtualMachines(ctx)
+//
+// When some profiles fail, the results of the other profiles are returned together with a
+// *ProfileErrors error of which Partial returns true. Other errors are typed as well:
+// *LoadError, *UnknownProfileError, *DuplicateProfileError and *SelectionError are returned for
+// config problems and *InvalidSortFieldError by the Sort methods.
+package cosmiccli
+
+import (
+	"time"
+
+	"github.com/shoekstra/cosmic-cli/internal/config"
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+)
+
+// Config contains the profiles and the settings used by a Session.
+type Config struct {
+	Profiles        map[string]Profile
+	DefaultProfiles []string            // Profiles used when no profiles are selected.
+	Groups          map[string][]string // Named selections of profiles.
+
+	CacheTTL      time.Duration // Time cached API responses are used for, 0 disables caching.
+	Parallelism   int           // Maximum number of profiles used concurrently, 0 for no limit.
+	RateLimit     float64       // Maximum requests per second per API endpoint, 0 for no limit.
+	RetryAttempts int           // Maximum number of attempts of a failed API call.
+	RetryBackoff  time.Duration // Delay before retrying a failed API call, doubled for every retry.
+	RetryJitter   float64       // Fraction of the backoff delay that is randomized.
+	Timeout       time.Duration // Maximum time the API calls of a profile may take, 0 for no limit.
+}
+
+// Profile contains the settings of a single API endpoint.
+type Profile = config.Profile
+
+// Resource types returned by a Session. Their slice types provide Sort and, where applicable,
+// FindByID and FindByName methods.
+type (
+	ACL               = cosmic.ACL
+	ACLs              = cosmic.ACLs
+	ACLRule           = cosmic.ACLRule
+	ACLRules          = cosmic.ACLRules
+	Network           = cosmic.Network
+	Networks          = cosmic.Networks
+	PrivateGateway    = cosmic.PrivateGateway
+	PrivateGateways   = cosmic.PrivateGateways
+	ProfileMeta       = cosmic.ProfileMeta
+	PublicIPAddress   = cosmic.PublicIPAddress
+	PublicIPAddresses = cosmic.PublicIPAddresses
+	StaticRoute       = cosmic.StaticRoute
+	StaticRoutes      = cosmic.StaticRoutes
+	VirtualMachine    = cosmic.VirtualMachine
+	VirtualMachines   = cosmic.VirtualMachines
+	VPC               = cosmic.VPC
+	VPCs              = cosmic.VPCs
+	WhoHasThisIP      = cosmic.WhoHasThisIP
+	WhoHasThisIPs     = cosmic.WhoHasThisIPs
+	WhoHasThisMac     = cosmic.WhoHasThisMac
+	WhoHasThisMacs    = cosmic.WhoHasThisMacs
+	Zone              = cosmic.Zone
+	Zones             = cosmic.Zones
+)
+
+// Error types.
+type (
+	DuplicateProfileError = config.DuplicateProfileError
+	InvalidSortFieldError = cosmic.InvalidSortFieldError
+	LoadError             = config.LoadError
+	ProfileError          = cosmic.ProfileError
+	ProfileErrors         = cosmic.ProfileErrors
+	SelectionError        = config.SelectionError
+	UnknownProfileError   = config.UnknownProfileError
+)
+
+// LoadConfig reads the config file and environment variables the same way cosmic-cli does; file
+// is used instead of searching for a config file when it is set. Settings that cosmic-cli sets
+// using flags, such as Parallelism, Timeout, RetryAttempts and CacheTTL, are zero unless they
+// are set in the config file, which disables them.
+func LoadConfig(file string) (*Config, error) {
+	c, err := config.LoadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		Profiles:        c.Profiles,
+		DefaultProfiles: c.DefaultProfiles,
+		Groups:          c.Groups,
+		CacheTTL:        c.CacheTTL,
+		Parallelism:     c.Parallelism,
+		RateLimit:       c.RateLimit,
+		RetryAttempts:   c.RetryAttempts,
+		RetryBackoff:    c.RetryBackoff,
+		RetryJitter:     c.RetryJitter,
+		Timeout:         c.Timeout,
+	}, nil
+}
+
+// config returns the cosmic-cli config of c using the profiles selected by selection.
+func (c *Config) config(selection string) *config.Config {
+	return &config.Config{
+		Profiles:        c.Profiles,
+		DefaultProfiles: c.DefaultProfiles,
+		Groups:          c.Groups,
+		Profile:         selection,
+		CacheTTL:        c.CacheTTL,
+		Parallelism:     c.Parallelism,
+		RateLimit:       c.RateLimit,
+		RetryAttempts:   c.RetryAttempts,
+		RetryBackoff:    c.RetryBackoff,
+		RetryJitter:     c.RetryJitter,
+		Timeout:         c.Timeout,
+	}
+}
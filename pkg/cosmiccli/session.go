@@ -0,0 +1,145 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmiccli
+
+import (
+	"context"
+
+	"github.com/shoekstra/cosmic-cli/internal/cosmic"
+)
+
+// Session runs API calls using a selection of the profiles of a config. It is safe for concurrent
+// use. Sessions don't share rate limits or other state with each other.
+type Session struct {
+	clients  map[string]*cosmic.Client
+	profiles []string
+}
+
+// NewSession returns a Session using the profiles of cfg selected by selection, which accepts the
+// same profile names, groups and patterns as the --profile flag of cosmic-cli, e.g. "ams*,!ams3".
+// The default_profiles of cfg, or all profiles, are used when selection is empty.
+func NewSession(cfg *Config, selection string) (*Session, error) {
+	c := cfg.config(selection)
+
+	if errs := c.DuplicateProfiles(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	profiles, err := c.SelectProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		clients:  cosmic.NewRegistry().NewAsyncClients(c),
+		profiles: profiles,
+	}, nil
+}
+
+// Profiles returns the names of the profiles used by the session, sorted.
+func (s *Session) Profiles() []string {
+	return append([]string{}, s.profiles...)
+}
+
+// ListACLs returns the network ACL lists of all profiles.
+func (s *Session) ListACLs(ctx context.Context) (ACLs, error) {
+	return cosmic.ListACLs(ctx, s.clients)
+}
+
+// ListACLRules returns the rules of the network ACL list with the given ID.
+func (s *Session) ListACLRules(ctx context.Context, aclID string) (ACLRules, error) {
+	return cosmic.ListACLRules(ctx, s.clients, aclID)
+}
+
+// ListNetworks returns the networks of all profiles.
+func (s *Session) ListNetworks(ctx context.Context) (Networks, error) {
+	return cosmic.ListNetworks(ctx, s.clients)
+}
+
+// ListPrivateGateways returns the VPC private gateways of all profiles.
+func (s *Session) ListPrivateGateways(ctx context.Context) (PrivateGateways, error) {
+	return cosmic.ListVPCPrivateGateways(ctx, s.clients)
+}
+
+// ListPublicIPAddresses returns the public IP addresses of all profiles.
+func (s *Session) ListPublicIPAddresses(ctx context.Context) (PublicIPAddresses, error) {
+	return cosmic.ListPublicIPAddresses(ctx, s.clients)
+}
+
+// ListVirtualMachines returns the virtual machines of all profiles.
+func (s *Session) ListVirtualMachines(ctx context.Context) (VirtualMachines, error) {
+	return cosmic.ListVMs(ctx, s.clients)
+}
+
+// ListVPCs returns the VPCs of all profiles.
+func (s *Session) ListVPCs(ctx context.Context) (VPCs, error) {
+	return cosmic.ListVPCs(ctx, s.clients)
+}
+
+// ListVPCRoutes returns the static routes of the VPC with the given ID.
+func (s *Session) ListVPCRoutes(ctx context.Context, vpcID string) (StaticRoutes, error) {
+	return cosmic.ListVPCRoutes(ctx, s.clients, vpcID)
+}
+
+// ListZones returns the zones of all profiles.
+func (s *Session) ListZones(ctx context.Context) (Zones, error) {
+	return cosmic.ListZones(ctx, s.clients)
+}
+
+// FindVPCByID returns the VPC with the given ID; an error is returned if no profile, or more than
+// one profile, has a matching VPC.
+func (s *Session) FindVPCByID(ctx context.Context, id string) (*VPC, error) {
+	vpcs, err := cosmic.VPCGetAllByID(ctx, s.clients, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return vpcs[0], nil
+}
+
+// FindVPCByName returns the VPC with the given name; an error is returned if no profile, or more
+// than one profile, has a matching VPC.
+func (s *Session) FindVPCByName(ctx context.Context, name string) (*VPC, error) {
+	vpcs, err := cosmic.VPCGetAllByName(ctx, s.clients, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return vpcs[0], nil
+}
+
+// CreateVPCRoute adds a static route for cidr via nextHop to the VPC with the given ID, using the
+// profile the VPC exists in.
+func (s *Session) CreateVPCRoute(ctx context.Context, vpcID, nextHop, cidr string) error {
+	return cosmic.CreateVPCRoute(ctx, s.clients, vpcID, nextHop, cidr)
+}
+
+// DeleteVPCRoute removes the static route with the given ID, using the profile the route exists
+// in.
+func (s *Session) DeleteVPCRoute(ctx context.Context, id string) error {
+	return cosmic.DeleteVPCRoute(ctx, s.clients, id)
+}
+
+// WhoHasThisIP returns the resources using an IP address, together with their VPC and zone.
+func (s *Session) WhoHasThisIP(ctx context.Context, ip string) (WhoHasThisIPs, error) {
+	return cosmic.ListIP(ctx, s.clients, ip)
+}
+
+// WhoHasThisMac returns the resources using a MAC address, together with their VPC and zone.
+func (s *Session) WhoHasThisMac(ctx context.Context, mac string) (WhoHasThisMacs, error) {
+	return cosmic.ListMAC(ctx, s.clients, mac)
+}
@@ -0,0 +1,108 @@
+//
+// Copyright © 2019 Stephen Hoekstra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cosmiccli_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoekstra/cosmic-cli/pkg/cosmiccli"
+	"github.com/spf13/viper"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cosmiccli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.toml")
+	content := "default_profiles = [\"ams1\"]\n[profiles.ams1]\napi_url = \"https://ams1.test/client/api\"\n"
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := cosmiccli.LoadConfig(file)
+	if err != nil {
+		t.Fatalf("LoadConfig() returned an error: %s", err)
+	}
+	if cfg.Profiles["ams1"].APIURL != "https://ams1.test/client/api" || len(cfg.DefaultProfiles) != 1 {
+		t.Errorf("LoadConfig() returned %+v, want the settings of %s", cfg, file)
+	}
+
+	// The global viper belongs to the program using the package.
+	if viper.GetString("config") != "" || viper.IsSet("profiles") {
+		t.Errorf("LoadConfig() changed the global viper")
+	}
+}
+
+func TestSession(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("apiKey") == "invalid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"listvirtualmachinesresponse":{"errorcode":401,"errortext":"unable to verify user credentials"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"listvirtualmachinesresponse":{"count":1,"virtualmachine":[{"id":"%s","name":"web1"}]}}`, r.FormValue("apiKey"))
+	}))
+	defer ts.Close()
+
+	cfg := &cosmiccli.Config{
+		Profiles: map[string]cosmiccli.Profile{
+			"ams1": {APIURL: ts.URL, APIKey: "ams1", SecretKey: "secret", Region: "eu-west"},
+			"ams2": {APIURL: ts.URL, APIKey: "invalid", SecretKey: "secret"},
+			"ams3": {APIURL: ts.URL, APIKey: "ams3", SecretKey: "secret"},
+		},
+	}
+
+	if _, err := cosmiccli.NewSession(cfg, "fra*"); err == nil {
+		t.Errorf("NewSession() returned no error for a pattern matching no profiles")
+	} else if _, ok := err.(*cosmiccli.UnknownProfileError); !ok {
+		t.Errorf("NewSession() returned a %T, want a *cosmiccli.UnknownProfileError", err)
+	}
+
+	s, err := cosmiccli.NewSession(cfg, "ams*,!ams3")
+	if err != nil {
+		t.Fatalf("NewSession() returned an error: %s", err)
+	}
+	if got := fmt.Sprint(s.Profiles()); got != "[ams1 ams2]" {
+		t.Errorf("Profiles() = %s, want [ams1 ams2]", got)
+	}
+
+	vms, err := s.ListVirtualMachines(context.Background())
+
+	errs, ok := err.(*cosmiccli.ProfileErrors)
+	if !ok || !errs.Partial() || errs.Errors[0].Profile != "ams2" {
+		t.Errorf("ListVirtualMachines() returned error %v, want a partial error for ams2", err)
+	}
+	if len(vms) != 1 || vms[0].Id != "ams1" || vms[0].Profile != "ams1" || vms[0].Region != "eu-west" {
+		t.Errorf("ListVirtualMachines() returned %+v, want the VM of ams1", vms)
+	}
+
+	if err := vms.Sort("nope", false); err == nil {
+		t.Errorf("Sort() returned no error for an invalid field")
+	} else if _, ok := err.(*cosmiccli.InvalidSortFieldError); !ok {
+		t.Errorf("Sort() returned a %T, want a *cosmiccli.InvalidSortFieldError", err)
+	}
+}